@@ -0,0 +1,9 @@
+data "thoughtspot_tml" "sales" {
+  identifier        = "Sales Performance"
+  type              = "LIVEBOARD"
+  export_associated = true
+}
+
+output "sales_tml" {
+  value = data.thoughtspot_tml.sales.tml
+}
@@ -0,0 +1,5 @@
+resource "thoughtspot_metadata_copy" "finance" {
+  source_id    = "6d2c3d1e-0a8f-4c37-9a55-2f3b9e2f6d11"
+  name         = "Sales Performance - Finance"
+  worksheet_id = "c1a6e4b2-59d3-4f4b-8a0e-7b0d2e1f9a44"
+}
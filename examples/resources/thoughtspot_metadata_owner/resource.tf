@@ -0,0 +1,5 @@
+resource "thoughtspot_metadata_owner" "this" {
+  metadata_type        = "LIVEBOARD"
+  metadata_identifiers = ["Sales Performance"]
+  user_identifier      = "analytics-admin"
+}
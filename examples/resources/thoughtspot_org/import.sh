@@ -0,0 +1,2 @@
+# Orgs can be imported by ID or name
+terraform import thoughtspot_org.acme 1532970192
@@ -0,0 +1,11 @@
+resource "thoughtspot_org" "acme" {
+  name        = "acme"
+  description = "Acme Corp"
+  on_destroy  = "deactivate"
+}
+
+# Resources with an org_identifier can target the new org directly
+resource "thoughtspot_email_customization" "acme" {
+  org_identifier      = thoughtspot_org.acme.id
+  cta_button_bg_color = "#0b8a00"
+}
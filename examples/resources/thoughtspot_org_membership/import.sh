@@ -0,0 +1,2 @@
+# Memberships can be imported by the ID or name of the org
+terraform import thoughtspot_org_membership.acme 1532970192
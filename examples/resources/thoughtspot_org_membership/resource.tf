@@ -0,0 +1,11 @@
+resource "thoughtspot_org_membership" "acme" {
+  org_identifier = thoughtspot_org.acme.id
+  users          = ["alice", "bob"]
+}
+
+# Only the listed groups stay in the org
+resource "thoughtspot_org_membership" "acme_groups" {
+  org_identifier = thoughtspot_org.acme.id
+  groups         = ["acme-analysts"]
+  authoritative  = true
+}
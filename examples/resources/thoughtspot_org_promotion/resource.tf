@@ -0,0 +1,20 @@
+resource "thoughtspot_org_promotion" "sales" {
+  source_org = "DEV"
+  metadata_ids = [
+    "a3f1c2d4-5b6e-4f70-8a91-b2c3d4e5f601", # Sales model
+    "6d2c3d1e-0a8f-4c37-9a55-2f3b9e2f6d11", # Sales Performance liveboard
+  ]
+
+  targets = {
+    "PROD EMEA" = {
+      connections = {
+        "Snowflake DEV" = "Snowflake PROD EMEA"
+      }
+    }
+    "PROD US" = {
+      connections = {
+        "Snowflake DEV" = "Snowflake PROD US"
+      }
+    }
+  }
+}
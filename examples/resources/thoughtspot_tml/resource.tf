@@ -1,6 +1,7 @@
 resource "thoughtspot_metadata" "this" {
-  metadata {
-    tml = <<EOT
+  objects = {
+    sales_performance = {
+      tml = <<EOT
 guid: d084c256-e284-4fc4-b80c-111cb6064300
 liveboard:
   name: Sales Performance
@@ -42,5 +43,6 @@ liveboard:
       size: MEDIUM_SMALL
 
 EOT
+    }
   }
 }
@@ -0,0 +1,2 @@
+# Users can be imported by GUID or name
+terraform import thoughtspot_user.this 2a3b4c5d-6e7f-4a8b-9c0d-1e2f3a4b5c6d
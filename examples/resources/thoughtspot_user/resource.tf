@@ -0,0 +1,22 @@
+variable "initial_password" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+resource "thoughtspot_user" "this" {
+  name             = "jane.doe"
+  display_name     = "Jane Doe"
+  email            = "jane.doe@example.com"
+  password         = var.initial_password
+  password_version = 1
+  account_type     = "LOCAL_USER"
+  orgs             = ["Primary"]
+  groups           = ["analysts"]
+  preferred_locale = "en-US"
+  notify_on_share  = false
+
+  # Keep the content of the user when they leave
+  on_destroy            = "deactivate"
+  transfer_ownership_to = "svc-terraform"
+}
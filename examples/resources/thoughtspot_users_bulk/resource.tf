@@ -0,0 +1,32 @@
+variable "default_password" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+resource "thoughtspot_users_bulk" "finance" {
+  default_password = var.default_password
+  password_version = 1
+
+  users = [
+    {
+      name         = "alice"
+      display_name = "Alice"
+      email        = "alice@example.com"
+      groups       = ["finance"]
+    },
+    {
+      name         = "bob"
+      display_name = "Bob"
+      email        = "bob@example.com"
+      groups       = ["finance", "analysts"]
+    },
+  ]
+}
+
+# name,display_name,email,groups
+# carol,Carol,carol@example.com,sales|analysts
+resource "thoughtspot_users_bulk" "sales" {
+  csv_file         = "${path.module}/users/sales.csv"
+  default_password = var.default_password
+}
@@ -0,0 +1,268 @@
+package datasources
+
+import (
+	"context"
+	"fmt"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &TmlDataSource{}
+	_ datasource.DataSourceWithConfigure = &TmlDataSource{}
+)
+
+func NewTmlDataSource() datasource.DataSource {
+	return &TmlDataSource{}
+}
+
+type TmlDataSource struct {
+	client *thoughtspot.Client
+}
+
+type TmlDataSourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	Identifier       types.String `tfsdk:"identifier"`
+	Type             types.String `tfsdk:"type"`
+	ExportAssociated types.Bool   `tfsdk:"export_associated"`
+	IncludeGuid      types.Bool   `tfsdk:"include_guid"`
+	IncludeObjId     types.Bool   `tfsdk:"include_obj_id"`
+	Format           types.String `tfsdk:"format"`
+	Name             types.String `tfsdk:"name"`
+	Tml              types.String `tfsdk:"tml"`
+	Tmls             types.Map    `tfsdk:"tmls"`
+}
+
+// Metadata returns the data source type name.
+func (d *TmlDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_tml"
+}
+
+// Schema defines the schema for the data source.
+func (d *TmlDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "GUID of the object.",
+			},
+			"identifier": schema.StringAttribute{
+				Required:    true,
+				Description: "GUID or name of the object.",
+			},
+			"type": schema.StringAttribute{
+				Optional:    true,
+				Description: "Type of metadata. Required if identifier is a name. Accepts `LIVEBOARD`, `ANSWER`, `LOGICAL_TABLE`, `CONNECTION`",
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{
+						"LIVEBOARD",
+						"ANSWER",
+						"LOGICAL_TABLE",
+						"CONNECTION"}...),
+				},
+			},
+			"export_associated": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Flag to also export the objects the object depends on.",
+			},
+			"include_guid": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Flag to include guids in the TML. Defaults to `true`.",
+			},
+			"include_obj_id": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Flag to include object ids in the TML.",
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Format of the TML. Accepts `YAML`, `JSON`. Defaults to `YAML`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("YAML", "JSON"),
+				},
+			},
+			"name": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the object.",
+			},
+			"tml": schema.StringAttribute{
+				Computed:    true,
+				Description: "TML of the object.",
+			},
+			"tmls": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "TML of the object and, with `export_associated`, of its dependencies, keyed by GUID.",
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *TmlDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state TmlDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Format.IsNull() {
+		state.Format = types.StringValue("YAML")
+	}
+
+	includeGuid := true
+	if !state.IncludeGuid.IsNull() {
+		includeGuid = state.IncludeGuid.ValueBool()
+	}
+
+	cr := models.ExportMetadataTMLRequest{
+		Metadata: []models.ExportMetadataTypeInput{{
+			Identifier: state.Identifier.ValueString(),
+			Type:       state.Type.ValueString(),
+		}},
+		ExportAssociated: state.ExportAssociated.ValueBool(),
+		EdocFormat:       state.Format.ValueString(),
+		ExportOptions: models.ExportOptions{
+			IncludeGuid:  includeGuid,
+			IncludeObjId: state.IncludeObjId.ValueBool(),
+		},
+	}
+
+	c, err := d.client.ExportMetadataTML(cr)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read TML",
+			"Could not export TML of "+state.Identifier.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(c) == 0 {
+		resp.Diagnostics.AddError(
+			"Unable to Read TML",
+			"No TML found for "+state.Identifier.ValueString(),
+		)
+		return
+	}
+
+	tmls := map[string]string{}
+	for _, metadata := range c {
+		if metadata.Info.Status.StatusCode == "ERROR" {
+			resp.Diagnostics.AddError(
+				"Unable to Read TML",
+				"Could not export TML of "+metadata.Info.Name+": "+metadata.Info.Status.ErrorMessage,
+			)
+			continue
+		}
+		tmls[metadata.Info.Id] = metadata.Edoc
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The export of associated objects isn't ordered, find the requested one
+	i := requestedTml(c, state.Identifier.ValueString(), state.Type.ValueString())
+	if i < 0 {
+		resp.Diagnostics.AddError(
+			"Unable to Read TML",
+			"The export doesn't include the TML of "+state.Identifier.ValueString(),
+		)
+		return
+	}
+
+	state.ID = types.StringValue(c[i].Info.Id)
+	state.Name = types.StringValue(c[i].Info.Name)
+	state.Tml = types.StringValue(c[i].Edoc)
+
+	m, diags := types.MapValueFrom(ctx, types.StringType, tmls)
+	resp.Diagnostics.Append(diags...)
+	state.Tmls = m
+
+	// Set state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// tmlMetadataTypes maps the object keys of TML to their metadata type.
+var tmlMetadataTypes = map[string]string{
+	"table":      "LOGICAL_TABLE",
+	"worksheet":  "LOGICAL_TABLE",
+	"model":      "LOGICAL_TABLE",
+	"view":       "LOGICAL_TABLE",
+	"sql_view":   "LOGICAL_TABLE",
+	"answer":     "ANSWER",
+	"liveboard":  "LIVEBOARD",
+	"pinboard":   "LIVEBOARD",
+	"connection": "CONNECTION",
+}
+
+// tmlMetadataType returns the metadata type of the object a TML defines.
+func tmlMetadataType(tml string) string {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return ""
+	}
+
+	for key := range doc {
+		if t, ok := tmlMetadataTypes[key]; ok {
+			return t
+		}
+	}
+
+	return ""
+}
+
+// requestedTml returns the index of the exported object with the given guid,
+// or with the given name and type, or -1 if the export doesn't include it.
+func requestedTml(c []models.ExportMetadataTMLResponse, identifier string, metadataType string) int {
+	for i := range c {
+		if c[i].Info.Id == identifier {
+			return i
+		}
+	}
+
+	for i := range c {
+		if c[i].Info.Name == identifier && (metadataType == "" || tmlMetadataType(c[i].Edoc) == metadataType) {
+			return i
+		}
+	}
+
+	// A single object is the requested one, whichever way it was named
+	if len(c) == 1 {
+		return 0
+	}
+
+	return -1
+}
+
+// Configure adds the provider configured client to the data source.
+func (d *TmlDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*thoughtspot.Client)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *thoughtspot.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
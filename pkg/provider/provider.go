@@ -35,10 +35,13 @@ type thoughtspotProvider struct {
 }
 
 type thoughtspotProviderModel struct {
-	Host          types.String `tfsdk:"host"`
-	Username      types.String `tfsdk:"username"`
-	Password      types.String `tfsdk:"password"`
-	OrgIdentifier types.String `tfsdk:"org_identifier"`
+	Host               types.String `tfsdk:"host"`
+	Username           types.String `tfsdk:"username"`
+	Password           types.String `tfsdk:"password"`
+	OrgIdentifier      types.String `tfsdk:"org_identifier"`
+	DeletionProtection types.Bool   `tfsdk:"deletion_protection"`
+	RetainOnDestroy    types.Bool   `tfsdk:"retain_on_destroy"`
+	GuidMappingFile    types.String `tfsdk:"guid_mapping_file"`
 }
 
 func (p *thoughtspotProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -61,6 +64,18 @@ func (p *thoughtspotProvider) Schema(_ context.Context, _ provider.SchemaRequest
 			"org_identifier": schema.StringAttribute{
 				Required: true,
 			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Default for `deletion_protection` on resources which support it.",
+			},
+			"retain_on_destroy": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Default for `retain_on_destroy` on resources which support it.",
+			},
+			"guid_mapping_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Default for `guid_mapping_file` on resources which support it.",
+			},
 		},
 	}
 }
@@ -159,13 +174,23 @@ func (p *thoughtspotProvider) Configure(ctx context.Context, req provider.Config
 	// Make the Qlik client available during DataSource and Resource
 	// type Configure methods.
 	resp.DataSourceData = client
-	resp.ResourceData = client
+	resp.ResourceData = &resources.ProviderData{
+		Client:             client,
+		Host:               host,
+		Username:           username,
+		Password:           password,
+		OrgIdentifier:      org_identifier,
+		DeletionProtection: config.DeletionProtection.ValueBool(),
+		RetainOnDestroy:    config.RetainOnDestroy.ValueBool(),
+		GuidMappingFile:    config.GuidMappingFile.ValueString(),
+	}
 }
 
 // DataSources defines the data sources implemented in the provider.
 func (p *thoughtspotProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		datasources.NewCurrentUserInfoDataSource,
+		datasources.NewTmlDataSource,
 	}
 }
 
@@ -181,5 +206,12 @@ func (p *thoughtspotProvider) Resources(_ context.Context) []func() resource.Res
 		resources.NewCustomCalendarResource,
 		resources.NewEmailCustomizationResource,
 		resources.NewTagResource,
+		resources.NewMetadataOwnerResource,
+		resources.NewMetadataCopyResource,
+		resources.NewOrgPromotionResource,
+		resources.NewUserResource,
+		resources.NewUsersBulkResource,
+		resources.NewOrgResource,
+		resources.NewOrgMembershipResource,
 	}
 }
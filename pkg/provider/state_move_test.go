@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// TestMoveMetadataState moves the thoughtspot_metadata state fixture into
+// thoughtspot_tml, and checks packages with several objects are refused.
+func TestMoveMetadataState(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("creating provider server: %s", err)
+	}
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("getting provider schema: %s", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join("testdata", "state", "metadata_v1.json"))
+	if err != nil {
+		t.Fatalf("reading state fixture: %s", err)
+	}
+
+	move := func(raw []byte) *tfprotov6.MoveResourceStateResponse {
+		resp, err := server.MoveResourceState(ctx, &tfprotov6.MoveResourceStateRequest{
+			SourceProviderAddress: "registry.terraform.io/daniepett/thoughtspot",
+			SourceTypeName:        "thoughtspot_metadata",
+			SourceSchemaVersion:   1,
+			SourceState:           &tfprotov6.RawState{JSON: raw},
+			TargetTypeName:        "thoughtspot_tml",
+		})
+		if err != nil {
+			t.Fatalf("moving state: %s", err)
+		}
+		return resp
+	}
+
+	resp := move(raw)
+	assertNoErrors(t, resp.Diagnostics)
+
+	state, err := resp.TargetState.Unmarshal(schemas.ResourceSchemas["thoughtspot_tml"].ValueType())
+	if err != nil {
+		t.Fatalf("reading moved state: %s", err)
+	}
+
+	var attrs map[string]tftypes.Value
+	if err := state.As(&attrs); err != nil {
+		t.Fatalf("reading moved state: %s", err)
+	}
+
+	for name, want := range map[string]string{
+		"id":            "6d2c3d1e-0a8f-4c37-9a55-2f3b9e2f6d11",
+		"name":          "Sales Performance",
+		"type":          "liveboard",
+		"metadata_type": "LIVEBOARD",
+		"format":        "YAML",
+	} {
+		var got string
+		if err := attrs[name].As(&got); err != nil || got != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+	}
+
+	// A package with several objects can't be moved into one object
+	var source map[string]any
+	if err := json.Unmarshal(raw, &source); err != nil {
+		t.Fatalf("reading state fixture: %s", err)
+	}
+	objects := source["objects"].(map[string]any)
+	for _, o := range objects {
+		objects["second"] = o
+		break
+	}
+	raw, _ = json.Marshal(source)
+
+	resp = move(raw)
+	if len(resp.Diagnostics) == 0 || resp.Diagnostics[0].Severity != tfprotov6.DiagnosticSeverityError {
+		t.Errorf("moving a package with several objects: expected an error")
+	}
+}
@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// TestStateUpgrade upgrades a state fixture of every schema version of every
+// resource, found in testdata/state as <resource>_v<version>.json. Fixtures of
+// prior versions describe the same object as the fixture of the current
+// version, and must upgrade to it.
+func TestStateUpgrade(t *testing.T) {
+	ctx := context.Background()
+
+	server, err := providerserver.NewProtocol6WithError(New("test")())()
+	if err != nil {
+		t.Fatalf("creating provider server: %s", err)
+	}
+
+	schemas, err := server.GetProviderSchema(ctx, &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatalf("getting provider schema: %s", err)
+	}
+	assertNoErrors(t, schemas.Diagnostics)
+
+	for typeName, schema := range schemas.ResourceSchemas {
+		typ := schema.ValueType()
+		name := strings.TrimPrefix(typeName, "thoughtspot_")
+
+		t.Run(name, func(t *testing.T) {
+			current := upgradeStateFixture(t, server, typeName, schema.Version, fmt.Sprintf("%s_v%d.json", name, schema.Version), typ)
+
+			for version := int64(0); version < schema.Version; version++ {
+				fixture := fmt.Sprintf("%s_v%d.json", name, version)
+
+				t.Run(fixture, func(t *testing.T) {
+					upgraded := upgradeStateFixture(t, server, typeName, version, fixture, typ)
+
+					if diff, err := upgraded.Diff(current); err != nil || len(diff) > 0 {
+						t.Errorf("upgraded state differs from %s_v%d.json: %v %v", name, schema.Version, diff, err)
+					}
+				})
+			}
+		})
+	}
+}
+
+// upgradeStateFixture upgrades a state fixture and returns the upgraded
+// state.
+func upgradeStateFixture(t *testing.T, server tfprotov6.ProviderServer, typeName string, version int64, fixture string, typ tftypes.Type) tftypes.Value {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join("testdata", "state", fixture))
+	if err != nil {
+		t.Fatalf("reading state fixture: %s", err)
+	}
+
+	resp, err := server.UpgradeResourceState(context.Background(), &tfprotov6.UpgradeResourceStateRequest{
+		TypeName: typeName,
+		Version:  version,
+		RawState: &tfprotov6.RawState{JSON: raw},
+	})
+	if err != nil {
+		t.Fatalf("upgrading %s: %s", fixture, err)
+	}
+	assertNoErrors(t, resp.Diagnostics)
+
+	if resp.UpgradedState == nil {
+		t.Fatalf("upgrading %s: no upgraded state", fixture)
+	}
+
+	state, err := resp.UpgradedState.Unmarshal(typ)
+	if err != nil {
+		t.Fatalf("reading upgraded %s: %s", fixture, err)
+	}
+
+	return state
+}
+
+func assertNoErrors(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+}
@@ -35,6 +35,7 @@ func NewConnectionResource() resource.Resource {
 // orderResource is the resource implementation.
 type ConnectionResource struct {
 	client *thoughtspot.Client
+	data   *ProviderData
 }
 
 // orderResourceModel maps the resource schema data.
@@ -47,6 +48,9 @@ type ConnectionResourceModel struct {
 	Validate          types.Bool                                                   `tfsdk:"validate"`
 	Snowflake         types.Object                                                 `tfsdk:"snowflake"`
 	Redshift          types.Object                                                 `tfsdk:"redshift"`
+
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	RetainOnDestroy    types.Bool `tfsdk:"retain_on_destroy"`
 }
 
 type ConnectionSnowflakeModel struct {
@@ -97,6 +101,7 @@ func (r *ConnectionResource) Metadata(_ context.Context, req resource.MetadataRe
 // Schema defines the schema for the resource.
 func (r *ConnectionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 0,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -128,6 +133,14 @@ func (r *ConnectionResource) Schema(_ context.Context, _ resource.SchemaRequest,
 					},
 				},
 			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail on destroy instead of deleting the object. Defaults to the provider's `deletion_protection`.",
+			},
+			"retain_on_destroy": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only remove the object from state on destroy, keeping it on the server. Defaults to the provider's `retain_on_destroy`.",
+			},
 			"validate": schema.BoolAttribute{
 				Required: true,
 			},
@@ -223,18 +236,19 @@ func (r *ConnectionResource) Configure(_ context.Context, req resource.Configure
 		return
 	}
 
-	client, ok := req.ProviderData.(*thoughtspot.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *qlikcloud.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.data = data
 }
 
 // Create a new resource.
@@ -464,6 +478,10 @@ func (r *ConnectionResource) Delete(ctx context.Context, req resource.DeleteRequ
 		return
 	}
 
+	if !r.data.deleteOnDestroy(state.DeletionProtection, state.RetainOnDestroy, "connection '"+state.Name.ValueString()+"'", &resp.Diagnostics) {
+		return
+	}
+
 	err := r.client.DeleteConnection(state.ID.ValueString())
 
 	if err != nil {
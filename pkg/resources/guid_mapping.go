@@ -0,0 +1,174 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// guidMappingEntry is an entry of a GUID mapping file in the ThoughtSpot
+// deploy format. It maps the guid of an object in the source environment to
+// the guid of the same object in this one.
+type guidMappingEntry struct {
+	OriginalGuid string `json:"originalGuid"`
+	MappedGuid   string `json:"mappedGuid"`
+	Counter      int    `json:"counter"`
+}
+
+// guidMappingMu serializes access to mapping files, as resources sharing a
+// file are applied in parallel.
+var guidMappingMu sync.Mutex
+
+// guidMappingFile returns the mapping file of a resource, or the provider
+// default when the resource doesn't set one.
+func (d *ProviderData) guidMappingFile(v types.String) string {
+	if !v.IsNull() && !v.IsUnknown() {
+		return v.ValueString()
+	}
+	if d != nil {
+		return d.GuidMappingFile
+	}
+
+	return ""
+}
+
+// readGuidMapping reads a mapping file. A missing file is an empty mapping,
+// as the file is created on the first import into a new environment.
+func readGuidMapping(file string) ([]guidMappingEntry, error) {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []guidMappingEntry
+	if len(strings.TrimSpace(string(b))) == 0 {
+		return entries, nil
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// applyGuidMapping replaces the guids of the TML that have an entry in the
+// mapping file with the guid of the object in this environment.
+func applyGuidMapping(tml string, file string) (string, error) {
+	if file == "" {
+		return tml, nil
+	}
+
+	guidMappingMu.Lock()
+	entries, err := readGuidMapping(file)
+	guidMappingMu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	mapped := map[string]string{}
+	for _, e := range entries {
+		if e.OriginalGuid != "" && e.MappedGuid != "" {
+			mapped[e.OriginalGuid] = e.MappedGuid
+		}
+	}
+
+	values, err := tmlGuidPaths(tml)
+	if err != nil {
+		return "", err
+	}
+
+	var oldnew []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		if m, ok := mapped[v.Value]; ok && !seen[v.Value] {
+			oldnew = append(oldnew, v.Value, m)
+			seen[v.Value] = true
+		}
+	}
+
+	if len(oldnew) == 0 {
+		return tml, nil
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(tml), nil
+}
+
+// recordGuidMapping adds the guid pairs of an import to the mapping file,
+// keeping the entries of other objects as they are.
+func recordGuidMapping(file string, pairs []tmlGuidPair) error {
+	if file == "" {
+		return nil
+	}
+
+	guidMappingMu.Lock()
+	defer guidMappingMu.Unlock()
+
+	entries, err := readGuidMapping(file)
+	if err != nil {
+		return err
+	}
+
+	index := map[string]int{}
+	for i, e := range entries {
+		index[e.OriginalGuid] = i
+	}
+
+	changed := false
+	for _, p := range pairs {
+		if p.Original == "" || p.Computed == "" || p.Original == p.Computed {
+			continue
+		}
+
+		if i, ok := index[p.Original]; ok {
+			if entries[i].MappedGuid != p.Computed {
+				entries[i].MappedGuid = p.Computed
+				entries[i].Counter++
+				changed = true
+			}
+			continue
+		}
+
+		index[p.Original] = len(entries)
+		entries = append(entries, guidMappingEntry{OriginalGuid: p.Original, MappedGuid: p.Computed})
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, append(b, '\n'), 0o644)
+}
+
+// guidMappingPairs converts a guids attribute into guid pairs.
+func guidMappingPairs(ctx context.Context, guids types.List) ([]tmlGuidPair, diag.Diagnostics) {
+	var pairs []tmlGuidPair
+	if guids.IsNull() || guids.IsUnknown() {
+		return pairs, nil
+	}
+
+	var values []MetadataGuidModel
+	diags := guids.ElementsAs(ctx, &values, false)
+	for _, g := range values {
+		pairs = append(pairs, tmlGuidPair{
+			Original: g.Original.ValueString(),
+			Computed: g.Computed.ValueString(),
+		})
+	}
+
+	return pairs, diags
+}
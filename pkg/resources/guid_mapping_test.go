@@ -0,0 +1,214 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// TestReadGuidMapping reads the deploy format, and treats a missing or empty
+// file as an empty mapping.
+func TestReadGuidMapping(t *testing.T) {
+	dir := t.TempDir()
+
+	entries, err := readGuidMapping(filepath.Join(dir, "missing.json"))
+	if err != nil || entries != nil {
+		t.Errorf("got %v, %v for a missing file", entries, err)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte("\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = readGuidMapping(empty)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("got %v, %v for an empty file", entries, err)
+	}
+
+	file := filepath.Join(dir, "mapping.json")
+	content := `[{"originalGuid": "lb-original", "mappedGuid": "lb-mapped", "counter": 2}]`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = readGuidMapping(file)
+	if err != nil {
+		t.Fatalf("reading mapping: %s", err)
+	}
+
+	want := []guidMappingEntry{{OriginalGuid: "lb-original", MappedGuid: "lb-mapped", Counter: 2}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readGuidMapping(invalid); err == nil {
+		t.Errorf("expected an error for an invalid file")
+	}
+}
+
+// TestApplyGuidMapping replaces the guids of the TML which have a mapping.
+func TestApplyGuidMapping(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.json")
+	content := `[
+  {"originalGuid": "lb-original", "mappedGuid": "lb-mapped", "counter": 0},
+  {"originalGuid": "viz-1-original", "mappedGuid": "viz-1-mapped", "counter": 1},
+  {"originalGuid": "other", "mappedGuid": "other-mapped", "counter": 0}
+]`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := applyGuidMapping(testOriginalLiveboardTml, file)
+	if err != nil {
+		t.Fatalf("applying mapping: %s", err)
+	}
+
+	values, err := tmlGuidPaths(got)
+	if err != nil {
+		t.Fatalf("reading guid paths: %s", err)
+	}
+
+	want := []tmlGuidValue{
+		{Path: "guid", Value: "lb-mapped"},
+		{Path: "liveboard.visualizations[Viz_1].viz_guid", Value: "viz-1-mapped"},
+		{Path: "liveboard.visualizations[Viz_2].viz_guid", Value: "viz-2-original"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got %+v, want %+v", values, want)
+	}
+
+	// Without a file, or a mapping for its guids, the TML is kept as it is
+	if got, err := applyGuidMapping(testOriginalLiveboardTml, ""); err != nil || got != testOriginalLiveboardTml {
+		t.Errorf("got %q, %v without a mapping file", got, err)
+	}
+	if got, err := applyGuidMapping(testTableTml, file); err != nil || got != testTableTml {
+		t.Errorf("got %q, %v without mapped guids", got, err)
+	}
+}
+
+// TestRecordGuidMapping adds new pairs, updates the mapped guid and counter of
+// changed ones, and keeps the entries of other objects.
+func TestRecordGuidMapping(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.json")
+	content := `[{"originalGuid": "other", "mappedGuid": "other-mapped", "counter": 3}]`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := recordGuidMapping(file, []tmlGuidPair{
+		{Original: "lb-original", Computed: "lb-computed"},
+		{Original: "viz-1-original", Computed: "viz-1-computed"},
+		// Unchanged and incomplete pairs aren't recorded
+		{Original: "same", Computed: "same"},
+		{Original: "viz-2-original"},
+	})
+	if err != nil {
+		t.Fatalf("recording mapping: %s", err)
+	}
+
+	err = recordGuidMapping(file, []tmlGuidPair{
+		{Original: "lb-original", Computed: "lb-computed"},
+		{Original: "viz-1-original", Computed: "viz-1-recomputed"},
+	})
+	if err != nil {
+		t.Fatalf("recording mapping: %s", err)
+	}
+
+	entries, err := readGuidMapping(file)
+	if err != nil {
+		t.Fatalf("reading mapping: %s", err)
+	}
+
+	want := []guidMappingEntry{
+		{OriginalGuid: "other", MappedGuid: "other-mapped", Counter: 3},
+		{OriginalGuid: "lb-original", MappedGuid: "lb-computed"},
+		{OriginalGuid: "viz-1-original", MappedGuid: "viz-1-recomputed", Counter: 1},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got %+v, want %+v", entries, want)
+	}
+
+	// The file keeps the field names of the deploy format
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatalf("parsing mapping: %s", err)
+	}
+	for _, key := range []string{"originalGuid", "mappedGuid", "counter"} {
+		if _, ok := raw[0][key]; !ok {
+			t.Errorf("entry %v has no %s", raw[0], key)
+		}
+	}
+
+	// A mapping recorded by one import is applied by the next
+	tml := "guid: viz-1-original\n"
+	if got, err := applyGuidMapping(tml, file); err != nil || got != "guid: viz-1-recomputed\n" {
+		t.Errorf("got %q, %v after applying the recorded mapping", got, err)
+	}
+
+	// Without a file nothing is recorded
+	if err := recordGuidMapping("", []tmlGuidPair{{Original: "a", Computed: "b"}}); err != nil {
+		t.Errorf("got %v without a mapping file", err)
+	}
+}
+
+// TestRecordGuidMappingConcurrent records the guids of resources sharing a
+// file in parallel without losing any of them.
+func TestRecordGuidMappingConcurrent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "mapping.json")
+
+	const resources = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, resources)
+	for i := 0; i < resources; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- recordGuidMapping(file, []tmlGuidPair{{
+				Original: fmt.Sprintf("original-%02d", i),
+				Computed: fmt.Sprintf("computed-%02d", i),
+			}})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("recording mapping: %s", err)
+		}
+	}
+
+	entries, err := readGuidMapping(file)
+	if err != nil {
+		t.Fatalf("reading mapping: %s", err)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].OriginalGuid < entries[j].OriginalGuid
+	})
+
+	if len(entries) != resources {
+		t.Fatalf("got %d entries, want %d", len(entries), resources)
+	}
+	for i, e := range entries {
+		want := guidMappingEntry{
+			OriginalGuid: fmt.Sprintf("original-%02d", i),
+			MappedGuid:   fmt.Sprintf("computed-%02d", i),
+		}
+		if e != want {
+			t.Errorf("got entry %+v, want %+v", e, want)
+		}
+	}
+}
@@ -3,25 +3,32 @@ package resources
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/daniepett/thoughtspot-sdk-go"
 	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &MetadataResource{}
-	_ resource.ResourceWithConfigure = &MetadataResource{}
+	_ resource.Resource                 = &MetadataResource{}
+	_ resource.ResourceWithConfigure    = &MetadataResource{}
+	_ resource.ResourceWithModifyPlan   = &MetadataResource{}
+	_ resource.ResourceWithUpgradeState = &MetadataResource{}
 	// _ resource.ResourceWithImportState = &MetadataResource{}
 )
 
@@ -31,13 +38,19 @@ func NewMetadataResource() resource.Resource {
 
 type MetadataResource struct {
 	client *thoughtspot.Client
+	data   *ProviderData
 }
 
 // orderResourceModel maps the resource schema data.
 type MetadataResourceModel struct {
 	ID           types.String `tfsdk:"id"`
-	Metadata     types.List   `tfsdk:"metadata"`
+	Objects      types.Map    `tfsdk:"objects"`
 	ImportPolicy types.String `tfsdk:"import_policy"`
+	Variables    types.Map    `tfsdk:"variables"`
+	Format       types.String `tfsdk:"format"`
+	OnRemove     types.String `tfsdk:"on_remove"`
+
+	GuidMappingFile types.String `tfsdk:"guid_mapping_file"`
 }
 
 type MetadataGuidModel struct {
@@ -46,6 +59,23 @@ type MetadataGuidModel struct {
 }
 
 type MetadataExportModel struct {
+	ID    types.String `tfsdk:"id"`
+	Name  types.String `tfsdk:"name"`
+	Tml   types.String `tfsdk:"tml"`
+	Guids types.List   `tfsdk:"guids"`
+}
+
+// MetadataResourceModelV0 maps the schema before objects were keyed by name.
+type MetadataResourceModelV0 struct {
+	ID           types.String `tfsdk:"id"`
+	Metadata     types.List   `tfsdk:"metadata"`
+	ImportPolicy types.String `tfsdk:"import_policy"`
+	Variables    types.Map    `tfsdk:"variables"`
+	Format       types.String `tfsdk:"format"`
+	OnRemove     types.String `tfsdk:"on_remove"`
+}
+
+type MetadataExportModelV0 struct {
 	ID    types.String `tfsdk:"id"`
 	Tml   types.String `tfsdk:"tml"`
 	Guids types.List   `tfsdk:"guids"`
@@ -61,6 +91,7 @@ func (o MetadataGuidModel) attrTypes() map[string]attr.Type {
 func (o MetadataExportModel) attrTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"id":    types.StringType,
+		"name":  types.StringType,
 		"tml":   types.StringType,
 		"guids": types.ListType{ElemType: types.ObjectType{AttrTypes: MetadataGuidModel{}.attrTypes()}},
 	}
@@ -82,15 +113,45 @@ func (r *MetadataResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				},
 			},
 			"import_policy": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Computed:    true,
+				Description: "Policy for importing the package. `PARTIAL` keeps the objects which imported successfully and retries the others on the next apply. Accepts `ALL_OR_NONE`, `PARTIAL`, `VALIDATE_ONLY`",
+				Default:     stringdefault.StaticString("ALL_OR_NONE"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("ALL_OR_NONE", "PARTIAL", "VALIDATE_ONLY"),
+				},
 			},
-		},
-		Blocks: map[string]schema.Block{
-			"metadata": schema.ListNestedBlock{
-				PlanModifiers: []planmodifier.List{
-					listplanmodifier.UseStateForUnknown(),
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Format of the TML. Accepts `YAML`, `JSON`",
+				Default:     stringdefault.StaticString("YAML"),
+				Validators: []validator.String{
+					stringvalidator.OneOf(tmlFormats...),
+				},
+			},
+			"variables": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Values for `${name}` placeholders in the TML, substituted before import and restored on export. Escape placeholders as `$${name}` in inline HCL strings.",
+			},
+			"on_remove": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "What happens to objects removed from the package. `delete` deletes them on the server, `retain` leaves them. Accepts `delete`, `retain`",
+				Default:     stringdefault.StaticString("delete"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("delete", "retain"),
 				},
-				NestedObject: schema.NestedBlockObject{
+			},
+			"guid_mapping_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a GUID mapping file in the ThoughtSpot deploy format. GUIDs of the TML are replaced with the mapped GUIDs before import, and the GUIDs of new objects are added to the file. Objects are updated rather than created when a mapping exists. Defaults to the provider's `guid_mapping_file`.",
+			},
+			"objects": schema.MapNestedAttribute{
+				Required:    true,
+				Description: "Objects of the package, keyed by a name which stays stable when objects are added or removed.",
+				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"id": schema.StringAttribute{
 							Computed: true,
@@ -98,6 +159,10 @@ func (r *MetadataResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 								stringplanmodifier.UseStateForUnknown(),
 							},
 						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the object in the TML.",
+						},
 						"tml": schema.StringAttribute{
 							Required: true,
 						},
@@ -127,30 +192,571 @@ func (r *MetadataResource) Schema(_ context.Context, _ resource.SchemaRequest, r
 				},
 			},
 		},
+		Version: 1,
 	}
 }
 
+func (r *MetadataResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"id": schema.StringAttribute{
+						Computed: true,
+					},
+					"import_policy": schema.StringAttribute{
+						Optional: true,
+					},
+					"format": schema.StringAttribute{
+						Optional: true,
+						Computed: true,
+					},
+					"variables": schema.MapAttribute{
+						ElementType: types.StringType,
+						Optional:    true,
+					},
+					"on_remove": schema.StringAttribute{
+						Optional: true,
+						Computed: true,
+					},
+				},
+				Blocks: map[string]schema.Block{
+					"metadata": schema.ListNestedBlock{
+						NestedObject: schema.NestedBlockObject{
+							Attributes: map[string]schema.Attribute{
+								"id": schema.StringAttribute{
+									Computed: true,
+								},
+								"tml": schema.StringAttribute{
+									Required: true,
+								},
+								"guids": schema.ListNestedAttribute{
+									NestedObject: schema.NestedAttributeObject{
+										Attributes: map[string]schema.Attribute{
+											"original": schema.StringAttribute{
+												Computed: true,
+											},
+											"computed": schema.StringAttribute{
+												Computed: true,
+											},
+										},
+									},
+									Computed: true,
+								},
+							},
+						},
+					},
+				},
+			},
+			StateUpgrader: upgradeMetadataStateV0,
+		},
+	}
+}
+
+// upgradeMetadataStateV0 moves the metadata list into the objects map. The
+// objects are keyed by their guid until the plan matches them to the keys in
+// the configuration.
+func upgradeMetadataStateV0(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+	var prior MetadataResourceModelV0
+	diags := req.State.Get(ctx, &prior)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var metadata []MetadataExportModelV0
+	diags = prior.Metadata.ElementsAs(ctx, &metadata, false)
+	resp.Diagnostics.Append(diags...)
+
+	variables, diags := tmlVariables(ctx, prior.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objects := map[string]MetadataExportModel{}
+	for _, t := range metadata {
+		name := types.StringNull()
+		if o, err := parseTmlRefs(substituteTmlVariables(t.Tml.ValueString(), variables)); err == nil {
+			name = types.StringValue(o.Name)
+		}
+
+		objects[t.ID.ValueString()] = MetadataExportModel{
+			ID:    t.ID,
+			Name:  name,
+			Tml:   t.Tml,
+			Guids: t.Guids,
+		}
+	}
+
+	m, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, objects)
+	resp.Diagnostics.Append(diags...)
+
+	state := MetadataResourceModel{
+		ID:           prior.ID,
+		Objects:      m,
+		ImportPolicy: prior.ImportPolicy,
+		Variables:    prior.Variables,
+		Format:       prior.Format,
+		OnRemove:     prior.OnRemove,
+	}
+
+	// Attributes added without a schema version may be missing
+	if state.Format.IsNull() {
+		state.Format = types.StringValue("YAML")
+	}
+	if state.OnRemove.IsNull() {
+		state.OnRemove = types.StringValue("delete")
+	}
+	if state.ImportPolicy.IsNull() {
+		state.ImportPolicy = types.StringValue("ALL_OR_NONE")
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// metadataObjects returns the keys and objects of the package ordered by key.
+func metadataObjects(ctx context.Context, objects types.Map) ([]string, []MetadataExportModel, diag.Diagnostics) {
+	elements := map[string]MetadataExportModel{}
+	diags := objects.ElementsAs(ctx, &elements, false)
+
+	keys := slices.Sorted(maps.Keys(elements))
+
+	var values []MetadataExportModel
+	for _, key := range keys {
+		values = append(values, elements[key])
+	}
+
+	return keys, values, diags
+}
+
+// matchMetadataObject finds the object in candidates which was imported from
+// the TML, by the guid in the TML or else by identical TML once variables
+// are substituted in the TML of the candidate.
+func matchMetadataObject(ctx context.Context, tml string, candidates map[string]MetadataExportModel, variables map[string]string) (string, bool) {
+	guid := tmlGuid(tml)
+
+	for key, c := range candidates {
+		if guid == "" {
+			if substituteTmlVariables(c.Tml.ValueString(), variables) == tml {
+				return key, true
+			}
+			continue
+		}
+
+		if c.ID.ValueString() == guid {
+			return key, true
+		}
+
+		var guids []MetadataGuidModel
+		c.Guids.ElementsAs(ctx, &guids, false)
+		if len(guids) > 0 && guids[0].Original.ValueString() == guid {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
 // Configure adds the provider configured client to the resource.
 func (r *MetadataResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*thoughtspot.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *qlikcloud.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.data = data
+}
+
+func (r *MetadataResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan MetadataResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.Objects.IsUnknown() || plan.Variables.IsUnknown() {
+		return
+	}
+
+	keys, metadata, diags := metadataObjects(ctx, plan.Objects)
+	resp.Diagnostics.Append(diags...)
+
+	// Objects without an id failed to import and are retried, unless the
+	// package is only validated
+	retry := false
+	if plan.ImportPolicy.ValueString() != "VALIDATE_ONLY" {
+		for _, o := range metadata {
+			retry = retry || o.ID.IsNull()
+		}
+	}
+
+	if req.Plan.Raw.Equal(req.State.Raw) && !retry {
+		return
+	}
+
+	variables, diags := tmlVariables(ctx, plan.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var tmls []string
+	for _, t := range metadata {
+		if t.Tml.IsUnknown() {
+			return
+		}
+		tmls = append(tmls, substituteTmlVariables(t.Tml.ValueString(), variables))
+	}
+
+	_, unresolved, diags := stageMetadata(keys, tmls)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = r.checkMetadataDependencies(keys, unresolved)
+	resp.Diagnostics.Append(diags...)
+
+	// Objects whose key isn't in state yet may have been imported under
+	// another key, e.g. after a state upgrade keyed them by guid
+	candidates := map[string]MetadataExportModel{}
+	if !req.State.Raw.IsNull() {
+		var state MetadataResourceModel
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		stateKeys, stateMetadata, diags := metadataObjects(ctx, state.Objects)
+		resp.Diagnostics.Append(diags...)
+		for i, key := range stateKeys {
+			if !slices.Contains(keys, key) {
+				candidates[key] = stateMetadata[i]
+			}
+		}
+	}
+
+	objects := map[string]MetadataExportModel{}
+	for i, key := range keys {
+		o := metadata[i]
+
+		if refs, err := parseTmlRefs(tmls[i]); err == nil {
+			o.Name = types.StringValue(refs.Name)
+		}
+
+		if o.ID.IsNull() && plan.ImportPolicy.ValueString() != "VALIDATE_ONLY" {
+			o.ID = types.StringUnknown()
+			o.Guids = types.ListUnknown(types.ObjectType{AttrTypes: MetadataGuidModel{}.attrTypes()})
+		}
+
+		if o.ID.IsUnknown() {
+			if match, ok := matchMetadataObject(ctx, tmls[i], candidates, variables); ok {
+				o.ID = candidates[match].ID
+				o.Guids = candidates[match].Guids
+				delete(candidates, match)
+			}
+		}
+
+		objects[key] = o
+	}
+
+	m, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, objects)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Plan.SetAttribute(ctx, path.Root("objects"), m)
+	resp.Diagnostics.Append(diags...)
 }
 
-func exportTmlsMetadata(ctx context.Context, client *thoughtspot.Client, ids []string, tmls []string) (types.List, diag.Diagnostics) {
+// stageMetadata orders the TMLs of the package into import stages by the
+// references between them. A cycle is reported as an error.
+func stageMetadata(keys []string, tmls []string) ([][]int, map[int][]tmlRef, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	objects := make([]tmlObjectRefs, len(tmls))
+	for i, tml := range tmls {
+		o, err := parseTmlRefs(tml)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("objects").AtMapKey(keys[i]).AtName("tml"),
+				"Error parsing TML",
+				"Could not parse TML, unexpected error: "+err.Error(),
+			)
+			continue
+		}
+		objects[i] = o
+	}
+
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+
+	stages, cycle, unresolved := stageTmlObjects(objects)
+	if len(cycle) > 0 {
+		var names []string
+		for _, i := range cycle {
+			names = append(names, fmt.Sprintf("%q", objects[i].Name))
+		}
+		diags.AddAttributeError(
+			path.Root("objects"),
+			"Dependency cycle in metadata",
+			"The objects "+strings.Join(names, ", ")+" can't be ordered for import because their references form a cycle.",
+		)
+		return nil, nil, diags
+	}
+
+	return stages, unresolved, diags
+}
+
+// checkMetadataDependencies warns about objects referenced from outside of the
+// package which don't exist on the server.
+func (r *MetadataResource) checkMetadataDependencies(keys []string, unresolved map[int][]tmlRef) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	// The provider isn't configured yet during validation
+	if r.client == nil {
+		return diags
+	}
+
+	found := map[tmlRef]bool{}
+	for i := range unresolved {
+		for _, ref := range unresolved[i] {
+			exists, checked := found[ref]
+			if !checked {
+				identifier := ref.Fqn
+				if identifier == "" {
+					identifier = ref.Name
+				}
+
+				c, err := r.client.SearchMetadata(models.SearchMetadataRequest{
+					Metadata: []models.MetadataListItemInput{{
+						Identifier: identifier,
+						Type:       ref.Type,
+					}},
+				})
+				if err != nil {
+					diags.AddWarning(
+						"Could not check metadata dependency",
+						"Could not search for '"+identifier+"', unexpected error: "+err.Error(),
+					)
+					continue
+				}
+
+				exists = len(c) > 0
+				found[ref] = exists
+			}
+
+			// The dependency may be created in the same apply, e.g. by a
+			// thoughtspot_tml resource, so it's only a warning
+			if !exists {
+				diags.AddAttributeWarning(
+					path.Root("objects").AtMapKey(keys[i]).AtName("tml"),
+					"Missing metadata dependency",
+					"The TML refers to '"+ref.Name+ref.Fqn+"', which is not part of this package and doesn't exist on the server yet. The import fails unless it is created before the package.",
+				)
+			}
+		}
+	}
+
+	return diags
+}
+
+// metadataTmlPath is the path of the tml of an object of the package.
+func metadataTmlPath(key string) path.Path {
+	return path.Root("objects").AtMapKey(key).AtName("tml")
+}
+
+// importMetadataStaged imports the TMLs one stage at a time, so objects exist
+// before the objects referring to them are imported. Guids of objects created
+// in earlier stages are rewritten in the guid fields of later stages. The id
+// of an object which wasn't imported is empty. Import statuses are reported
+// at the path tmlPath returns for the key of the object.
+//
+// A failing stage stops the import, but the ids of the objects imported by
+// earlier stages are still returned, so callers can keep them in state
+// instead of orphaning them on the server.
+func importMetadataStaged(client *thoughtspot.Client, keys []string, tmls []string, stages [][]int, createNew bool, policy string, format string, tmlPath func(key string) path.Path) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// Nothing is created when validating, so the package is validated at once
+	if policy == "VALIDATE_ONLY" {
+		var all []int
+		for _, stage := range stages {
+			all = append(all, stage...)
+		}
+		stages = [][]int{all}
+	}
+
+	ids := make([]string, len(tmls))
+	created := map[string]string{}
+
+	for _, stage := range stages {
+		var stageTmls []string
+		for _, i := range stage {
+			tml, err := replaceTmlGuids(tmls[i], created, format)
+			if err != nil {
+				diags.AddAttributeError(
+					tmlPath(keys[i]),
+					"Error importing Metadata",
+					"Could not rewrite the guids of '"+keys[i]+"', unexpected error: "+err.Error(),
+				)
+				return ids, diags
+			}
+			stageTmls = append(stageTmls, tml)
+		}
+
+		cr := models.ImportMetadataTMLRequest{
+			MetadataTmls: stageTmls,
+			CreateNew:    createNew,
+			ImportPolicy: policy,
+		}
+
+		c, err := client.ImportMetadataTML(cr)
+		if err != nil {
+			diags.AddError(
+				"Error importing Metadata",
+				"Could not import metadata, unexpected error: "+err.Error(),
+			)
+			return ids, diags
+		}
+
+		if len(c) != len(stage) {
+			diags.AddError(
+				"Error importing Metadata",
+				fmt.Sprintf("Expected %d import results, got %d.", len(stage), len(c)),
+			)
+			return ids, diags
+		}
+
+		for k, i := range stage {
+			status := c[k].Response.Status
+			p := tmlPath(keys[i])
+
+			switch status.StatusCode {
+			case "ERROR":
+				if policy == "PARTIAL" {
+					diags.AddAttributeWarning(
+						p,
+						"Error importing Metadata",
+						"Could not import '"+keys[i]+"', it is retried on the next apply: "+status.ErrorMessage,
+					)
+				} else {
+					diags.AddAttributeError(
+						p,
+						"Error importing Metadata",
+						"Could not import '"+keys[i]+"', unexpected error: "+status.ErrorMessage,
+					)
+				}
+				continue
+			case "WARNING":
+				diags.AddAttributeWarning(
+					p,
+					"Warning importing Metadata",
+					"Imported '"+keys[i]+"' with warning: "+status.ErrorMessage,
+				)
+			}
+
+			if policy == "VALIDATE_ONLY" {
+				continue
+			}
+
+			ids[i] = c[k].Response.Header.IdGuid
+			if guid := tmlGuid(tmls[i]); guid != "" && guid != ids[i] {
+				created[guid] = ids[i]
+			}
+		}
+
+		if diags.HasError() {
+			// The failing stage was imported all or nothing
+			for _, i := range stage {
+				ids[i] = ""
+			}
+			return ids, diags
+		}
+	}
+
+	return ids, diags
+}
+
+// refreshMetadataObjects exports the objects which have an id. The other
+// objects keep their TML without an id, e.g. when they failed to import under
+// the PARTIAL policy.
+func refreshMetadataObjects(ctx context.Context, client *thoughtspot.Client, keys []string, ids []string, objects []MetadataExportModel, format string, variables map[string]string) (types.Map, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var exportKeys, exportIds, exportTmls []string
+	for i, key := range keys {
+		id := ids[i]
+		if id == "" && !objects[i].ID.IsUnknown() {
+			id = objects[i].ID.ValueString()
+		}
+		if id == "" {
+			continue
+		}
+
+		exportKeys = append(exportKeys, key)
+		exportIds = append(exportIds, id)
+		exportTmls = append(exportTmls, objects[i].Tml.ValueString())
+	}
+
+	exported := map[string]MetadataExportModel{}
+	if len(exportIds) > 0 {
+		ex, d := exportTmlsMetadata(ctx, client, exportKeys, exportIds, exportTmls, format, variables)
+		diags.Append(d...)
+		if diags.HasError() {
+			return types.MapNull(types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}), diags
+		}
+
+		diags.Append(ex.ElementsAs(ctx, &exported, false)...)
+	}
+
+	for i, key := range keys {
+		if _, ok := exported[key]; ok {
+			continue
+		}
+
+		name := objects[i].Name
+		if name.IsUnknown() {
+			name = types.StringNull()
+		}
+
+		exported[key] = MetadataExportModel{
+			ID:    types.StringNull(),
+			Name:  name,
+			Tml:   objects[i].Tml,
+			Guids: types.ListNull(types.ObjectType{AttrTypes: MetadataGuidModel{}.attrTypes()}),
+		}
+	}
+
+	m, d := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, exported)
+	diags.Append(d...)
+
+	return m, diags
+}
+
+func exportTmlsMetadata(ctx context.Context, client *thoughtspot.Client, keys []string, ids []string, tmls []string, format string, variables map[string]string) (types.Map, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
 	var emi []models.ExportMetadataTypeInput
@@ -162,9 +768,9 @@ func exportTmlsMetadata(ctx context.Context, client *thoughtspot.Client, ids []s
 
 	cr := models.ExportMetadataTMLRequest{
 		Metadata:   emi,
-		EdocFormat: "YAML",
+		EdocFormat: format,
 		ExportOptions: models.ExportOptions{
-			IncludeGuid: false,
+			IncludeGuid: true,
 		},
 	}
 
@@ -174,49 +780,89 @@ func exportTmlsMetadata(ctx context.Context, client *thoughtspot.Client, ids []s
 			"Error Reading Metadata",
 			"Could not read Metadata ID: "+err.Error(),
 		)
-		return types.ListNull(types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}), diags
+		return types.MapNull(types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}), diags
 	}
 
 	if len(c) == 0 {
-		return types.ListNull(types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}), diags
+		return types.MapNull(types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}), diags
 	}
 
-	var mems []MetadataExportModel
+	mems := map[string]MetadataExportModel{}
 	for i := range c {
-		re := regexp.MustCompile(`guid: ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-		ogids := re.FindAllStringSubmatch(tmls[i], -1)
-		cgids := re.FindAllStringSubmatch(c[i].Edoc, -1)
-		var guids []MetadataGuidModel
-
-		tmlExport := c[i].Edoc
-		for j := range ogids {
-			guid := MetadataGuidModel{
-				Original: types.StringValue(ogids[j][1]),
-				Computed: types.StringValue(cgids[j][1]),
-			}
-			tmlExport = strings.Replace(tmls[i], guid.Computed.ValueString(), guid.Original.ValueString(), 1)
-			guids = append(guids, guid)
+		rendered := substituteTmlVariables(tmls[i], variables)
+
+		pairs, err := mapTmlGuids(rendered, c[i].Edoc)
+		if err != nil {
+			diags.AddError(
+				"Error Reading Metadata",
+				"Could not parse exported TML of '"+keys[i]+"', unexpected error: "+err.Error(),
+			)
+			continue
+		}
 
+		var guids []MetadataGuidModel
+		for _, p := range pairs {
+			guids = append(guids, MetadataGuidModel{
+				Original: types.StringValue(p.Original),
+				Computed: types.StringValue(p.Computed),
+			})
 		}
+
+		tmlExport := templateTml(restoreTmlGuids(c[i].Edoc, pairs), rendered, tmls[i], variables, format)
 		lg, diag := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: MetadataGuidModel{}.attrTypes()}, guids)
 
 		diags.Append(diag...)
 
-		mems = append(mems, MetadataExportModel{
+		name := types.StringNull()
+		if refs, err := parseTmlRefs(c[i].Edoc); err == nil {
+			name = types.StringValue(refs.Name)
+		}
+
+		mems[keys[i]] = MetadataExportModel{
 			ID:    types.StringValue(c[i].Info.Id),
+			Name:  name,
 			Tml:   types.StringValue(tmlExport),
 			Guids: lg,
-		})
+		}
 
 	}
 
-	m, diag := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, mems)
+	m, diag := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, mems)
 
 	diags.Append(diag...)
 
 	return m, diags
 }
 
+// recordGuidMapping adds the guids of the imported objects to the mapping
+// file.
+func (r *MetadataResource) recordGuidMapping(ctx context.Context, file string, objects types.Map) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if file == "" {
+		return diags
+	}
+
+	_, metadata, d := metadataObjects(ctx, objects)
+	diags.Append(d...)
+
+	var pairs []tmlGuidPair
+	for _, t := range metadata {
+		p, d := guidMappingPairs(ctx, t.Guids)
+		diags.Append(d...)
+		pairs = append(pairs, p...)
+	}
+
+	if err := recordGuidMapping(file, pairs); err != nil {
+		diags.AddAttributeWarning(
+			path.Root("guid_mapping_file"),
+			"Error updating GUID mapping file",
+			"Could not write the GUIDs of the imported objects to "+file+": "+err.Error(),
+		)
+	}
+
+	return diags
+}
+
 // Create a new resource.
 func (r *MetadataResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -228,40 +874,74 @@ func (r *MetadataResource) Create(ctx context.Context, req resource.CreateReques
 	}
 
 	var tmls []string
-	var metadata []MetadataExportModel
-	diags = plan.Metadata.ElementsAs(ctx, &metadata, false)
+	keys, metadata, diags := metadataObjects(ctx, plan.Objects)
 	resp.Diagnostics.Append(diags...)
 
 	for _, t := range metadata {
 		tmls = append(tmls, t.Tml.ValueString())
 	}
 
-	cr := models.ImportMetadataTMLRequest{
-		MetadataTmls: tmls,
-		CreateNew:    true,
+	variables, diags := tmlVariables(ctx, plan.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	c, err := r.client.ImportMetadataTML(cr)
+	mappingFile := r.data.guidMappingFile(plan.GuidMappingFile)
+
+	var renderedTmls []string
+	for i, tml := range tmls {
+		rendered, err := applyGuidMapping(substituteTmlVariables(tml, variables), mappingFile)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("objects").AtMapKey(keys[i]).AtName("tml"),
+				"Error applying GUID mapping",
+				"Could not map the GUIDs of the TML with "+mappingFile+": "+err.Error(),
+			)
+			continue
+		}
+		renderedTmls = append(renderedTmls, rendered)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error importing Metadata",
-			"Could not import metadata , unexpected error: "+c[0].Response.Status.ErrorMessage,
-		)
+	stages, _, diags := stageMetadata(keys, renderedTmls)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var ids []string
-	for i := range c {
-		ids = append(ids, c[i].Response.Header.IdGuid)
+	// With a mapping file the objects keep their identity across
+	// environments, so mapped objects are updated instead of duplicated
+	ids, diags := importMetadataStaged(r.client, keys, renderedTmls, stages, mappingFile == "", plan.ImportPolicy.ValueString(), plan.Format.ValueString(), metadataTmlPath)
+	resp.Diagnostics.Append(diags...)
+
+	// Objects imported by earlier stages are kept in state, so they are
+	// deleted when the tainted resource is replaced instead of being orphaned
+	if resp.Diagnostics.HasError() && !slices.ContainsFunc(ids, func(id string) bool { return id != "" }) {
+		return
 	}
 
-	ex, _ := exportTmlsMetadata(ctx, r.client, ids, tmls)
+	ex, diags := refreshMetadataObjects(ctx, r.client, keys, ids, metadata, plan.Format.ValueString(), variables)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
 
 	// Map response body to schema and populate Computed attribute values
-	plan.ID = types.StringValue(ids[0])
+	plan.ID = types.StringNull()
+	for _, id := range ids {
+		if id != "" {
+			plan.ID = types.StringValue(id)
+			break
+		}
+	}
 
-	plan.Metadata = ex
+	plan.Objects = ex
+
+	diags = r.recordGuidMapping(ctx, mappingFile, ex)
+	resp.Diagnostics.Append(diags...)
 
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
@@ -278,19 +958,24 @@ func (r *MetadataResource) Read(ctx context.Context, req resource.ReadRequest, r
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 
-	var tmls []string
 	var ids []string
 
-	var metadata []MetadataExportModel
-	diags = state.Metadata.ElementsAs(ctx, &metadata, false)
+	keys, metadata, diags := metadataObjects(ctx, state.Objects)
+	resp.Diagnostics.Append(diags...)
 	for _, t := range metadata {
-		tmls = append(tmls, t.Tml.ValueString())
 		ids = append(ids, t.ID.ValueString())
 	}
 
-	ex, _ := exportTmlsMetadata(ctx, r.client, ids, tmls)
+	variables, diags := tmlVariables(ctx, state.Variables)
+	resp.Diagnostics.Append(diags...)
 
-	state.Metadata = ex
+	ex, diags := refreshMetadataObjects(ctx, r.client, keys, ids, metadata, state.Format.ValueString(), variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Objects = ex
 
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
@@ -310,103 +995,146 @@ func (r *MetadataResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
+	// Get current state
+	var state MetadataResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	var formattedTmls []string
-	var tmls []string
-	var metadata []MetadataExportModel
-	var originalIds []string
-	diags = plan.Metadata.ElementsAs(ctx, &metadata, false)
+	keys, metadata, diags := metadataObjects(ctx, plan.Objects)
 	resp.Diagnostics.Append(diags...)
 
-	for _, t := range metadata {
+	variables, diags := tmlVariables(ctx, plan.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mappingFile := r.data.guidMappingFile(plan.GuidMappingFile)
+
+	for i, t := range metadata {
+		// Guids of objects which weren't imported yet are unknown
 		var guids []MetadataGuidModel
-		diags = t.Guids.ElementsAs(ctx, &guids, false)
+		diags = t.Guids.ElementsAs(ctx, &guids, true)
 		resp.Diagnostics.Append(diags...)
-		tml := t.Tml.ValueString()
-		tmls = append(tmls, tml)
+		computed := map[string]string{}
 		for _, guid := range guids {
-			tml = strings.Replace(tml, guid.Original.ValueString(), guid.Computed.ValueString(), 1)
-
+			computed[guid.Original.ValueString()] = guid.Computed.ValueString()
+		}
+		tml, err := replaceTmlGuids(substituteTmlVariables(t.Tml.ValueString(), variables), computed, plan.Format.ValueString())
+		if err == nil {
+			tml, err = applyGuidMapping(tml, mappingFile)
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("objects").AtMapKey(keys[i]).AtName("tml"),
+				"Error applying GUID mapping",
+				"Could not map the GUIDs of the TML with "+mappingFile+": "+err.Error(),
+			)
+			continue
 		}
 		formattedTmls = append(formattedTmls, tml)
-		originalIds = append(originalIds, t.ID.ValueString())
 	}
-
-	cr := models.ImportMetadataTMLRequest{
-		MetadataTmls: formattedTmls,
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	c, err := r.client.ImportMetadataTML(cr)
-
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error importing metadata",
-			"Could not import metadata , unexpected error: "+c[0].Response.Status.ErrorMessage,
-		)
+	stages, _, diags := stageMetadata(keys, formattedTmls)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	if len(c) == 0 {
-		resp.State.RemoveResource(ctx)
+	// An empty package has nothing to import, all its objects are removed
+	ids := make([]string, len(keys))
+	importFailed := false
+	if len(keys) > 0 {
+		ids, diags = importMetadataStaged(r.client, keys, formattedTmls, stages, false, plan.ImportPolicy.ValueString(), plan.Format.ValueString(), metadataTmlPath)
+		resp.Diagnostics.Append(diags...)
 
-		return
+		// Objects imported before a failing stage are kept in state below, so
+		// new objects aren't orphaned on the server
+		importFailed = diags.HasError()
 	}
 
-	var ids []string
-	for _, r := range c {
-		ids = append(ids, r.Response.Header.IdGuid)
+	ex, diags := refreshMetadataObjects(ctx, r.client, keys, ids, metadata, plan.Format.ValueString(), variables)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
 	}
 
-	ex, diag := exportTmlsMetadata(ctx, r.client, ids, tmls)
-
-	resp.Diagnostics.Append(diag...)
-	// fmt.Print("This is the read", ex)
-	// Map response body to schema and populate Computed attribute values
-	var newMetadata []MetadataExportModel
-	diags = ex.ElementsAs(ctx, &newMetadata, false)
+	_, newMetadata, diags := metadataObjects(ctx, ex)
 	resp.Diagnostics.Append(diags...)
 
-	for _, item := range newMetadata {
-		fmt.Print("This is the new tml", item.Tml)
+	var currentIds []string
+	for _, t := range newMetadata {
+		if !t.ID.IsNull() {
+			currentIds = append(currentIds, t.ID.ValueString())
+		}
 	}
 
-	plan.ID = types.StringValue(ids[0])
-	plan.Metadata = ex
-
-	// var deletedIds []models.DeleteMetadataTypeInput
-
-	// for _, val1 := range originalIds {
-	// 	found := false
+	// Map response body to schema and populate Computed attribute values
+	if plan.ID.IsUnknown() {
+		plan.ID = types.StringNull()
+		if len(currentIds) > 0 {
+			plan.ID = types.StringValue(currentIds[0])
+		}
+	}
+	plan.Objects = ex
 
-	// 	for _, val2 := range ids {
-	// 		if val1 == val2 {
-	// 			found = true
-	// 			break
-	// 		}
-	// 	}
-	// 	if !found {
-	// 		fmt.Print("Not found", val1)
-	// 		deletedIds = append(deletedIds, models.DeleteMetadataTypeInput{
-	// 			Identifier: val1,
-	// 		})
-	// 	}
-	// }
+	diags = r.recordGuidMapping(ctx, mappingFile, ex)
+	resp.Diagnostics.Append(diags...)
 
-	// fmt.Print("these are deleted", deletedIds)
+	// Objects are only deleted once the package imported, so a failed
+	// import doesn't leave it without the objects it replaced
+	if plan.OnRemove.ValueString() != "retain" && !importFailed {
+		stateKeys, stateMetadata, diags := metadataObjects(ctx, state.Objects)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			return
+		}
 
-	// if len(deletedIds) > 0 {
-	// 	cr := models.DeleteMetadataRequest{
-	// 		Metadata: deletedIds,
-	// 	}
-	// 	err := r.client.DeleteMetadata(cr)
+		// Delete objects which are no longer part of the package
+		var deletedIds []models.DeleteMetadataTypeInput
+		removed := map[string]MetadataExportModel{}
+		for i, t := range stateMetadata {
+			if !t.ID.IsNull() && !slices.Contains(currentIds, t.ID.ValueString()) {
+				deletedIds = append(deletedIds, models.DeleteMetadataTypeInput{
+					Identifier: t.ID.ValueString(),
+				})
+				removed[stateKeys[i]] = t
+			}
+		}
 
-	// 	if err != nil {
-	// 		resp.Diagnostics.AddError(
-	// 			"Error deleting Metadata",
-	// 			"Could not Metadata, unexpected error: "+err.Error(),
-	// 		)
-	// 		return
-	// 	}
-	// }
+		if len(deletedIds) > 0 {
+			err := r.client.DeleteMetadata(models.DeleteMetadataRequest{
+				Metadata: deletedIds,
+			})
+
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error deleting Metadata",
+					"Could not delete metadata removed from the package, unexpected error: "+err.Error(),
+				)
+
+				// Removed objects stay in state, so the next apply retries
+				// deleting them
+				objects := map[string]MetadataExportModel{}
+				diags = ex.ElementsAs(ctx, &objects, false)
+				resp.Diagnostics.Append(diags...)
+				for key, t := range removed {
+					if _, ok := objects[key]; !ok {
+						objects[key] = t
+					}
+				}
+				plan.Objects, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: MetadataExportModel{}.attrTypes()}, objects)
+				resp.Diagnostics.Append(diags...)
+			}
+		}
+	}
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -428,15 +1156,21 @@ func (r *MetadataResource) Delete(ctx context.Context, req resource.DeleteReques
 		Metadata: []models.DeleteMetadataTypeInput{},
 	}
 
-	var metadata []MetadataExportModel
-
-	diags = state.Metadata.ElementsAs(ctx, &metadata, false)
+	_, metadata, diags := metadataObjects(ctx, state.Objects)
 	resp.Diagnostics.Append(diags...)
 
 	for _, t := range metadata {
+		// Objects without an id were never imported
+		if t.ID.IsNull() {
+			continue
+		}
 		cr.Metadata = append(cr.Metadata, models.DeleteMetadataTypeInput{Identifier: t.ID.ValueString()})
 	}
 
+	if len(cr.Metadata) == 0 {
+		return
+	}
+
 	err := r.client.DeleteMetadata(cr)
 
 	if err != nil {
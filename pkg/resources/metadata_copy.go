@@ -0,0 +1,314 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &MetadataCopyResource{}
+	_ resource.ResourceWithConfigure = &MetadataCopyResource{}
+	// _ resource.ResourceWithImportState = &MetadataCopyResource{}
+)
+
+func NewMetadataCopyResource() resource.Resource {
+	return &MetadataCopyResource{}
+}
+
+type MetadataCopyResource struct {
+	client *thoughtspot.Client
+}
+
+type MetadataCopyResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	SourceId     types.String `tfsdk:"source_id"`
+	Name         types.String `tfsdk:"name"`
+	WorksheetId  types.String `tfsdk:"worksheet_id"`
+	MetadataType types.String `tfsdk:"metadata_type"`
+}
+
+// Metadata returns the resource type name.
+func (r *MetadataCopyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata_copy"
+}
+
+// Schema defines the schema for the resource.
+func (r *MetadataCopyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "GUID of the copy.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source_id": schema.StringAttribute{
+				Required:    true,
+				Description: "GUID of the object to copy. Changing it creates a new copy.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the copy.",
+			},
+			"worksheet_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "GUID of the worksheet or model the copy should use instead of the one of the source. Removing it creates a new copy using the worksheet of the source.",
+				PlanModifiers: []planmodifier.String{
+					// Updating the copy keeps its references, so only a new
+					// copy uses the worksheet of the source again
+					stringplanmodifier.RequiresReplaceIf(
+						func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							resp.RequiresReplace = !req.StateValue.IsNull() && req.PlanValue.IsNull()
+						},
+						"Removing the worksheet creates a new copy using the worksheet of the source.",
+						"Removing the worksheet creates a new copy using the worksheet of the source.",
+					),
+				},
+			},
+			"metadata_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "Type of metadata of the copy.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *MetadataCopyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+// importCopy exports the object with the given id, renames and retargets it,
+// and imports it again. Exporting the source with createNew set creates the
+// copy, exporting the copy itself updates it in place.
+func (r *MetadataCopyResource) importCopy(plan *MetadataCopyResourceModel, id string, createNew bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	c, err := r.client.ExportMetadataTML(models.ExportMetadataTMLRequest{
+		Metadata: []models.ExportMetadataTypeInput{{
+			Identifier: id,
+		}},
+		EdocFormat: "YAML",
+		ExportOptions: models.ExportOptions{
+			IncludeGuid: true,
+		},
+	})
+	if err != nil {
+		diags.AddError(
+			"Error copying Metadata",
+			"Could not export "+id+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	if len(c) == 0 || c[0].Info.Status.StatusCode == "ERROR" {
+		message := "object not found"
+		if len(c) > 0 {
+			message = c[0].Info.Status.ErrorMessage
+		}
+		diags.AddError(
+			"Error copying Metadata",
+			"Could not export "+id+", unexpected error: "+message,
+		)
+		return diags
+	}
+
+	objectType, _, err := tmlObjectType(c[0].Edoc)
+	if err == nil && objectType == "" {
+		err = fmt.Errorf("unknown object type")
+	}
+	if err != nil {
+		diags.AddError(
+			"Error copying Metadata",
+			"Could not parse TML of "+id+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	tml, err := copyTml(c[0].Edoc, plan.Name.ValueString(), plan.WorksheetId.ValueString(), !createNew, "YAML")
+	if err != nil {
+		diags.AddError(
+			"Error copying Metadata",
+			"Could not update TML of "+id+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	ic, err := r.client.ImportMetadataTML(models.ImportMetadataTMLRequest{
+		MetadataTmls: []string{tml},
+		ImportPolicy: "ALL_OR_NONE",
+		CreateNew:    createNew,
+	})
+	if err != nil {
+		diags.AddError(
+			"Error importing TML",
+			"Could not import tml , unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	if len(ic) == 0 || ic[0].Response.Status.StatusCode == "ERROR" {
+		message := "no import status returned"
+		if len(ic) > 0 {
+			message = ic[0].Response.Status.ErrorMessage
+		}
+		diags.AddError(
+			"Error importing TML",
+			"Could not import tml , unexpected error: "+message,
+		)
+		return diags
+	}
+
+	plan.ID = types.StringValue(ic[0].Response.Header.IdGuid)
+	plan.MetadataType = types.StringValue(tmlMetadataTypes[objectType])
+
+	return diags
+}
+
+// Create a new resource.
+func (r *MetadataCopyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan MetadataCopyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = r.importCopy(&plan, plan.SourceId.ValueString(), true)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *MetadataCopyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state MetadataCopyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c, err := r.client.SearchMetadata(models.SearchMetadataRequest{
+		Metadata: []models.MetadataListItemInput{{
+			Identifier: state.ID.ValueString(),
+			Type:       state.MetadataType.ValueString(),
+		}},
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Metadata",
+			"Could not read copy "+state.Name.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(c) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Name = types.StringValue(c[0].MetadataName)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *MetadataCopyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan MetadataCopyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = r.importCopy(&plan, plan.ID.ValueString(), false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *MetadataCopyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state MetadataCopyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteMetadata(models.DeleteMetadataRequest{
+		Metadata: []models.DeleteMetadataTypeInput{{
+			Identifier: state.ID.ValueString(),
+			Type:       state.MetadataType.ValueString(),
+		}},
+	})
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting Metadata",
+			"Could not delete copy, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+// func (r *MetadataCopyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+// 	// Retrieve import ID and save to id attribute
+// 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// }
@@ -0,0 +1,296 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &MetadataOwnerResource{}
+	_ resource.ResourceWithConfigure = &MetadataOwnerResource{}
+	// _ resource.ResourceWithImportState = &MetadataOwnerResource{}
+)
+
+func NewMetadataOwnerResource() resource.Resource {
+	return &MetadataOwnerResource{}
+}
+
+type MetadataOwnerResource struct {
+	client *thoughtspot.Client
+}
+
+type MetadataOwnerResourceModel struct {
+	ID                  types.String `tfsdk:"id"`
+	MetadataType        types.String `tfsdk:"metadata_type"`
+	MetadataIdentifiers types.Set    `tfsdk:"metadata_identifiers"`
+	UserIdentifier      types.String `tfsdk:"user_identifier"`
+}
+
+// Metadata returns the resource type name.
+func (r *MetadataOwnerResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_metadata_owner"
+}
+
+// Schema defines the schema for the resource.
+func (r *MetadataOwnerResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"metadata_type": schema.StringAttribute{
+				Required:    true,
+				Description: "Type of metadata. Accepts `LIVEBOARD`, `ANSWER`, `LOGICAL_TABLE`",
+				Validators: []validator.String{
+					stringvalidator.OneOf([]string{
+						"LIVEBOARD",
+						"ANSWER",
+						"LOGICAL_TABLE"}...),
+				},
+			},
+			"metadata_identifiers": schema.SetAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+				Description: "Unique IDs or names of the metadata objects. Note: All the names should belong to same metadata_type",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"user_identifier": schema.StringAttribute{
+				Required:    true,
+				Description: "Unique ID or name of the user to set as author of the objects.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *MetadataOwnerResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+// assignAuthor transfers the ownership of the objects to the user.
+func (r *MetadataOwnerResource) assignAuthor(plan MetadataOwnerResourceModel, ids []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(ids) == 0 {
+		return diags
+	}
+
+	var m []models.AuthorMetadataTypeInput
+	for _, id := range ids {
+		m = append(m, models.AuthorMetadataTypeInput{Identifier: id, Type: plan.MetadataType.ValueString()})
+	}
+
+	cr := models.AssignChangeAuthorRequest{
+		Metadata:       m,
+		UserIdentifier: plan.UserIdentifier.ValueString(),
+	}
+
+	err := r.client.AssignChangeAuthor(cr)
+	if err != nil {
+		diags.AddError(
+			"Error changing metadata owner",
+			"Could not change metadata owner, unexpected error: "+err.Error(),
+		)
+	}
+
+	return diags
+}
+
+// Create a new resource.
+func (r *MetadataOwnerResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan MetadataOwnerResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mi, diags := setStrings(ctx, plan.MetadataIdentifiers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = r.assignAuthor(plan, mi)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = types.StringValue(mi[0] + "|" + plan.UserIdentifier.ValueString())
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *MetadataOwnerResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state MetadataOwnerResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mi := make([]string, 0, len(state.MetadataIdentifiers.Elements()))
+	diags = state.MetadataIdentifiers.ElementsAs(ctx, &mi, false)
+	resp.Diagnostics.Append(diags...)
+
+	var m []models.MetadataListItemInput
+	for _, id := range mi {
+		m = append(m, models.MetadataListItemInput{Identifier: id, Type: state.MetadataType.ValueString()})
+	}
+
+	c, err := r.client.SearchMetadata(models.SearchMetadataRequest{
+		Metadata: m,
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading metadata owner",
+			"Could not read metadata owner, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	if len(c) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	u, err := r.client.SearchUsers(models.SearchUsersRequest{
+		UserIdentifier: state.UserIdentifier.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading metadata owner",
+			"Could not read user "+state.UserIdentifier.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	// The user can be set by ID or name, the header has both
+	user := map[string]bool{state.UserIdentifier.ValueString(): true}
+	if len(u) > 0 {
+		user[u[0].Id] = true
+		user[u[0].Name] = true
+	}
+
+	// Objects owned by someone else are dropped, so they are reassigned on
+	// the next apply. Deleted objects are kept as they are, there is
+	// nothing left to reassign
+	var ids []string
+	for _, id := range mi {
+		owned := true
+		for _, metadata := range c {
+			if id != metadata.MetadataId && id != metadata.MetadataName {
+				continue
+			}
+
+			header := metadata.MetadataHeader
+			owned = user[header.Author] || user[header.AuthorName]
+			break
+		}
+
+		if owned {
+			ids = append(ids, id)
+		}
+	}
+
+	state.MetadataIdentifiers, diags = types.SetValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *MetadataOwnerResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan, state MetadataOwnerResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mi, diags := setStrings(ctx, plan.MetadataIdentifiers)
+	resp.Diagnostics.Append(diags...)
+	stateIds, diags := setStrings(ctx, state.MetadataIdentifiers)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only the added and drifted objects need a new author, unless the user
+	// changed. Objects deleted since they were assigned stay in the state, so
+	// they aren't assigned again
+	if plan.UserIdentifier.Equal(state.UserIdentifier) && plan.MetadataType.Equal(state.MetadataType) {
+		mi = missingStrings(mi, stateIds)
+	}
+
+	diags = r.assignAuthor(plan, mi)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete only removes the resource from state, the objects keep their author.
+// Ownership can't be given back, as the previous author isn't known.
+func (r *MetadataOwnerResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
+
+// func (r *MetadataOwnerResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+// 	// Retrieve import ID and save to id attribute
+// 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// }
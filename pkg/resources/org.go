@@ -0,0 +1,309 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &OrgResource{}
+	_ resource.ResourceWithConfigure   = &OrgResource{}
+	_ resource.ResourceWithImportState = &OrgResource{}
+)
+
+func NewOrgResource() resource.Resource {
+	return &OrgResource{}
+}
+
+// OrgResource manages an org. The org APIs only work in the primary org, so
+// the resource uses a client for the primary org whichever org the provider
+// is scoped to.
+type OrgResource struct {
+	data *ProviderData
+}
+
+type OrgResourceModel struct {
+	ID          types.String `tfsdk:"id"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Active      types.Bool   `tfsdk:"active"`
+	OnDestroy   types.String `tfsdk:"on_destroy"`
+}
+
+// Metadata returns the resource type name.
+func (r *OrgResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_org"
+}
+
+// Schema defines the schema for the resource.
+func (r *OrgResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "ID of the org, to use as `org_identifier` of the provider or of resources.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the org.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "Description of the org.",
+			},
+			"active": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the org is active. Users can't sign in to inactive orgs. Defaults to `true`.",
+			},
+			"on_destroy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("delete"),
+				Description: "What to do with the org on destroy. `deactivate` keeps the org and its objects but makes it inactive, `delete` deletes it. Defaults to `delete`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("deactivate", "delete"),
+				},
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *OrgResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+// orgStatus returns the status of the org in the API for active.
+func orgStatus(active types.Bool) string {
+	if active.ValueBool() {
+		return "ACTIVE"
+	}
+	return "IN_ACTIVE"
+}
+
+// Create a new resource.
+func (r *OrgResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan OrgResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c, err := client.CreateOrg(models.CreateOrgRequest{
+		Name:        plan.Name.ValueString(),
+		Description: plan.Description.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating Org",
+			"Could not create org, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(strconv.Itoa(c.Id))
+
+	// Orgs are created active
+	if !plan.Active.ValueBool() {
+		err := client.UpdateOrg(plan.ID.ValueString(), models.UpdateOrgRequest{
+			Name:        plan.Name.ValueString(),
+			Description: plan.Description.ValueString(),
+			Status:      orgStatus(plan.Active),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error creating Org",
+				"Could not deactivate org, unexpected error: "+err.Error(),
+			)
+			plan.Active = types.BoolValue(true)
+		}
+	}
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *OrgResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state OrgResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c, err := client.SearchOrgs(models.SearchOrgsRequest{
+		OrgIdentifier: state.ID.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Org",
+			"Could not read Org ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(c) == 0 {
+		resp.State.RemoveResource(ctx)
+
+		return
+	}
+
+	m := c[0]
+
+	state.ID = types.StringValue(strconv.Itoa(m.Id))
+	state.Name = types.StringValue(m.Name)
+	state.Active = types.BoolValue(m.Status == "ACTIVE")
+
+	if m.Description != "" || !state.Description.IsNull() {
+		state.Description = types.StringValue(m.Description)
+	}
+
+	// Imported orgs don't have on_destroy yet
+	if state.OnDestroy.IsNull() {
+		state.OnDestroy = types.StringValue("delete")
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan OrgResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := client.UpdateOrg(plan.ID.ValueString(), models.UpdateOrgRequest{
+		Name:        plan.Name.ValueString(),
+		Description: plan.Description.ValueString(),
+		Status:      orgStatus(plan.Active),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating Org",
+			"Could not update Org, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state OrgResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.OnDestroy.ValueString() == "deactivate" {
+		err := client.UpdateOrg(state.ID.ValueString(), models.UpdateOrgRequest{
+			Name:        state.Name.ValueString(),
+			Description: state.Description.ValueString(),
+			Status:      orgStatus(types.BoolValue(false)),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting Org",
+				"Could not deactivate Org, unexpected error: "+err.Error(),
+			)
+		}
+		return
+	}
+
+	err := client.DeleteOrg(state.ID.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting Org",
+			"Could not delete Org, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *OrgResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to id attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
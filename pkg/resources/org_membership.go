@@ -0,0 +1,554 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &OrgMembershipResource{}
+	_ resource.ResourceWithConfigure   = &OrgMembershipResource{}
+	_ resource.ResourceWithImportState = &OrgMembershipResource{}
+)
+
+func NewOrgMembershipResource() resource.Resource {
+	return &OrgMembershipResource{}
+}
+
+// OrgMembershipResource manages the members of an org. Members can only be
+// managed from the primary org, so the resource uses a client for the
+// primary org whichever org the provider is scoped to.
+type OrgMembershipResource struct {
+	data *ProviderData
+}
+
+type OrgMembershipResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	OrgIdentifier types.String `tfsdk:"org_identifier"`
+	Users         types.Set    `tfsdk:"users"`
+	Groups        types.Set    `tfsdk:"groups"`
+	Authoritative types.Bool   `tfsdk:"authoritative"`
+}
+
+// Metadata returns the resource type name.
+func (r *OrgMembershipResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_org_membership"
+}
+
+// Schema defines the schema for the resource.
+func (r *OrgMembershipResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"org_identifier": schema.StringAttribute{
+				Required:    true,
+				Description: "ID or name of the org.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"users": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Names of the users to add to the org, if not defined Terraform will not manage the users of the org",
+				Validators: []validator.Set{
+					setvalidator.AtLeastOneOf(path.MatchRoot("groups")),
+				},
+			},
+			"groups": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Names of the groups to add to the org, if not defined Terraform will not manage the groups of the org",
+			},
+			"authoritative": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Remove the users and groups of the org which are not listed, instead of only adding the listed ones. Defaults to `false`.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *OrgMembershipResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.data = data
+}
+
+// setStrings returns the elements of a set, or nil for a null set.
+func setStrings(ctx context.Context, s types.Set) ([]string, diag.Diagnostics) {
+	if s.IsNull() || s.IsUnknown() {
+		return nil, nil
+	}
+
+	values := make([]string, 0, len(s.Elements()))
+	diags := s.ElementsAs(ctx, &values, false)
+	sort.Strings(values)
+
+	return values, diags
+}
+
+// missingStrings returns the values of a which are not in b.
+func missingStrings(a []string, b []string) []string {
+	in := map[string]bool{}
+	for _, v := range b {
+		in[v] = true
+	}
+
+	missing := []string{}
+	for _, v := range a {
+		if !in[v] {
+			missing = append(missing, v)
+		}
+	}
+
+	return missing
+}
+
+// commonStrings returns the values of a which are also in b.
+func commonStrings(a []string, b []string) []string {
+	return missingStrings(a, missingStrings(a, b))
+}
+
+// updateMembers adds, removes or replaces the users or the groups of an org.
+// Users and groups are updated in separate calls, so replacing one of them
+// leaves the other as it is.
+func updateMembers(client *thoughtspot.Client, org string, users []string, groups []string, operation string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if users != nil && (len(users) > 0 || operation == "REPLACE") {
+		err := client.UpdateOrg(org, models.UpdateOrgRequest{
+			UserIdentifiers: users,
+			Operation:       operation,
+		})
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("users"),
+				"Error updating Org",
+				"Could not update the users of Org '"+org+"', unexpected error: "+err.Error(),
+			)
+		}
+	}
+
+	if groups != nil && (len(groups) > 0 || operation == "REPLACE") {
+		err := client.UpdateOrg(org, models.UpdateOrgRequest{
+			GroupIdentifiers: groups,
+			Operation:        operation,
+		})
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("groups"),
+				"Error updating Org",
+				"Could not update the groups of Org '"+org+"', unexpected error: "+err.Error(),
+			)
+		}
+	}
+
+	return diags
+}
+
+// searchAllUserGroups returns the groups matching the request, paging
+// through the search until a page isn't full.
+func searchAllUserGroups(client *thoughtspot.Client, req models.SearchUserGroupsRequest) ([]models.UserGroupResponse, error) {
+	var groups []models.UserGroupResponse
+	for offset := 0; ; offset += userSearchPageSize {
+		req.RecordOffset = strconv.Itoa(offset)
+		req.RecordSize = strconv.Itoa(userSearchPageSize)
+
+		c, err := client.SearchUserGroups(req)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, c...)
+
+		if len(c) < userSearchPageSize {
+			return groups, nil
+		}
+	}
+}
+
+// orgMembers returns the names of all users and groups of an org.
+func orgMembers(client *thoughtspot.Client, org string) ([]string, []string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	u, err := searchAllUsers(client, models.SearchUsersRequest{
+		OrgIdentifiers: []string{org},
+	})
+	if err != nil {
+		diags.AddError(
+			"Error Reading Org",
+			"Could not read the users of Org '"+org+"': "+err.Error(),
+		)
+		return nil, nil, diags
+	}
+
+	g, err := searchAllUserGroups(client, models.SearchUserGroupsRequest{
+		OrgIdentifiers: []string{org},
+	})
+	if err != nil {
+		diags.AddError(
+			"Error Reading Org",
+			"Could not read the groups of Org '"+org+"': "+err.Error(),
+		)
+		return nil, nil, diags
+	}
+
+	users := make([]string, len(u))
+	for i := range u {
+		users[i] = u[i].Name
+	}
+
+	groups := make([]string, len(g))
+	for i := range g {
+		groups[i] = g[i].Name
+	}
+
+	return users, groups, diags
+}
+
+// orgMembershipPrivateKey is the private state key of orgMembershipPrivate.
+const orgMembershipPrivateKey = "members"
+
+// orgMembershipPrivate is kept in the private state of a membership, so the
+// configured members are known when the membership is refreshed or deleted.
+type orgMembershipPrivate struct {
+	// Users and Groups are the configured members, which are removed from
+	// the org when the membership is deleted
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// BuiltinUsers and BuiltinGroups are the members ThoughtSpot kept when an
+	// authoritative membership replaced the members of the org, like the
+	// administrators and the system groups it adds to every org
+	BuiltinUsers  []string `json:"builtin_users,omitempty"`
+	BuiltinGroups []string `json:"builtin_groups,omitempty"`
+}
+
+// privateState is the private state of a resource request or response.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// getOrgMembershipPrivate reads the private state of a membership. Imported
+// memberships don't have any.
+func getOrgMembershipPrivate(ctx context.Context, private privateState) (orgMembershipPrivate, bool, diag.Diagnostics) {
+	var members orgMembershipPrivate
+
+	value, diags := private.GetKey(ctx, orgMembershipPrivateKey)
+	if diags.HasError() || value == nil {
+		return members, false, diags
+	}
+
+	if err := json.Unmarshal(value, &members); err != nil {
+		diags.AddError(
+			"Error Reading Org membership",
+			"Could not read the private state of the membership: "+err.Error(),
+		)
+		return members, false, diags
+	}
+
+	return members, true, diags
+}
+
+// setOrgMembershipPrivate records the configured members of a membership
+// after they were applied. Authoritative memberships also record the members
+// the org still has besides them, which ThoughtSpot didn't allow to remove.
+func setOrgMembershipPrivate(ctx context.Context, private privateState, client *thoughtspot.Client, org string, users []string, groups []string, authoritative bool) diag.Diagnostics {
+	members := orgMembershipPrivate{
+		Users:  users,
+		Groups: groups,
+	}
+
+	if authoritative {
+		orgUsers, orgGroups, diags := orgMembers(client, org)
+		if diags.HasError() {
+			return diags
+		}
+
+		if users != nil {
+			members.BuiltinUsers = missingStrings(orgUsers, users)
+		}
+		if groups != nil {
+			members.BuiltinGroups = missingStrings(orgGroups, groups)
+		}
+	}
+
+	value, err := json.Marshal(members)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError(
+			"Error updating Org",
+			"Could not record the members of Org '"+org+"': "+err.Error(),
+		)
+		return diags
+	}
+
+	return private.SetKey(ctx, orgMembershipPrivateKey, value)
+}
+
+// Create a new resource.
+func (r *OrgMembershipResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan OrgMembershipResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, diags := setStrings(ctx, plan.Users)
+	resp.Diagnostics.Append(diags...)
+	groups, diags := setStrings(ctx, plan.Groups)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	operation := "ADD"
+	if plan.Authoritative.ValueBool() {
+		operation = "REPLACE"
+	}
+
+	diags = updateMembers(client, plan.OrgIdentifier.ValueString(), users, groups, operation)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = setOrgMembershipPrivate(ctx, resp.Private, client, plan.OrgIdentifier.ValueString(), users, groups, plan.Authoritative.ValueBool())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.OrgIdentifier
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *OrgMembershipResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state OrgMembershipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, groups, diags := orgMembers(client, state.OrgIdentifier.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	members, _, diags := getOrgMembershipPrivate(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Authoritative memberships track every member of the org, except the
+	// ones ThoughtSpot kept when they were applied. Additive ones only track
+	// the listed members, and drop the removed ones so they are added again
+	// on the next apply
+	users = missingStrings(users, members.BuiltinUsers)
+	groups = missingStrings(groups, members.BuiltinGroups)
+	if !state.Users.IsNull() {
+		stateUsers, diags := setStrings(ctx, state.Users)
+		resp.Diagnostics.Append(diags...)
+		if !state.Authoritative.ValueBool() {
+			users = commonStrings(stateUsers, users)
+		}
+		state.Users, diags = types.SetValueFrom(ctx, types.StringType, users)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	if !state.Groups.IsNull() {
+		stateGroups, diags := setStrings(ctx, state.Groups)
+		resp.Diagnostics.Append(diags...)
+		if !state.Authoritative.ValueBool() {
+			groups = commonStrings(stateGroups, groups)
+		}
+		state.Groups, diags = types.SetValueFrom(ctx, types.StringType, groups)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgMembershipResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan, state OrgMembershipResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, diags := setStrings(ctx, plan.Users)
+	resp.Diagnostics.Append(diags...)
+	groups, diags := setStrings(ctx, plan.Groups)
+	resp.Diagnostics.Append(diags...)
+	stateUsers, diags := setStrings(ctx, state.Users)
+	resp.Diagnostics.Append(diags...)
+	stateGroups, diags := setStrings(ctx, state.Groups)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	org := plan.OrgIdentifier.ValueString()
+
+	// Remove the members which are no longer listed, including all members
+	// of a list which is no longer managed
+	removedUsers := missingStrings(stateUsers, users)
+	removedGroups := missingStrings(stateGroups, groups)
+
+	if plan.Authoritative.ValueBool() {
+		diags = updateMembers(client, org, users, groups, "REPLACE")
+		resp.Diagnostics.Append(diags...)
+
+		// Replacing a list already removed its other members
+		if users != nil {
+			removedUsers = nil
+		}
+		if groups != nil {
+			removedGroups = nil
+		}
+	} else {
+		diags = updateMembers(client, org, users, groups, "ADD")
+		resp.Diagnostics.Append(diags...)
+	}
+
+	diags = updateMembers(client, org, removedUsers, removedGroups, "REMOVE")
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = setOrgMembershipPrivate(ctx, resp.Private, client, org, users, groups, plan.Authoritative.ValueBool())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.OrgIdentifier
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgMembershipResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state OrgMembershipResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	client, diags := r.data.primaryOrgClient()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	users, diags := setStrings(ctx, state.Users)
+	resp.Diagnostics.Append(diags...)
+	groups, diags := setStrings(ctx, state.Groups)
+	resp.Diagnostics.Append(diags...)
+	members, ok, diags := getOrgMembershipPrivate(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Only remove the configured members. The state of an authoritative
+	// membership also has the members added outside of Terraform since the
+	// last apply
+	if ok {
+		users = commonStrings(users, members.Users)
+		groups = commonStrings(groups, members.Groups)
+	}
+
+	diags = updateMembers(client, state.OrgIdentifier.ValueString(), users, groups, "REMOVE")
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *OrgMembershipResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to id attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("org_identifier"), req.ID)...)
+}
@@ -0,0 +1,752 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &OrgPromotionResource{}
+	_ resource.ResourceWithConfigure  = &OrgPromotionResource{}
+	_ resource.ResourceWithModifyPlan = &OrgPromotionResource{}
+	// _ resource.ResourceWithImportState = &OrgPromotionResource{}
+)
+
+func NewOrgPromotionResource() resource.Resource {
+	return &OrgPromotionResource{}
+}
+
+type OrgPromotionResource struct {
+	client *thoughtspot.Client
+	data   *ProviderData
+}
+
+type OrgPromotionResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	SourceOrg    types.String `tfsdk:"source_org"`
+	MetadataIds  types.Set    `tfsdk:"metadata_ids"`
+	Targets      types.Map    `tfsdk:"targets"`
+	SourceHashes types.Map    `tfsdk:"source_hashes"`
+}
+
+type OrgPromotionTargetModel struct {
+	Connections types.Map `tfsdk:"connections"`
+	Guids       types.Map `tfsdk:"guids"`
+}
+
+func (o OrgPromotionTargetModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"connections": types.MapType{ElemType: types.StringType},
+		"guids":       types.MapType{ElemType: types.StringType},
+	}
+}
+
+// Metadata returns the resource type name.
+func (r *OrgPromotionResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_org_promotion"
+}
+
+// Schema defines the schema for the resource.
+func (r *OrgPromotionResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source_org": schema.StringAttribute{
+				Required:    true,
+				Description: "Unique ID or name of the org to promote the objects from.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"metadata_ids": schema.SetAttribute{
+				Required:    true,
+				ElementType: types.StringType,
+				Description: "GUIDs of the objects to promote in the source org. Objects referring to each other are imported in dependency order.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+				},
+			},
+			"targets": schema.MapNestedAttribute{
+				Required:    true,
+				Description: "Orgs to promote the objects to, keyed by unique ID or name of the org.",
+				Validators: []validator.Map{
+					mapvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"connections": schema.MapAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "Map of connection name in the source org to the name of the connection to use in the target org.",
+						},
+						"guids": schema.MapAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+							Description: "Map of GUID in the source org to the GUID of the promoted object in the target org.",
+						},
+					},
+				},
+			},
+			"source_hashes": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Hash of the TML of each promoted object at the time of promotion. The objects are promoted again when the source changes.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *OrgPromotionResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+	r.data = data
+}
+
+// orgClient returns the client for an org, reporting errors against p.
+func (r *OrgPromotionResource) orgClient(org string, p path.Path) (*thoughtspot.Client, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	client, err := r.data.orgClient(org)
+	if err != nil {
+		diags.AddAttributeError(
+			p,
+			"Unable to Thoughtspot Client",
+			"Could not sign in to org '"+org+"': "+err.Error(),
+		)
+	}
+
+	return client, diags
+}
+
+// exportSources exports the TML of the objects from the source org, keyed by
+// GUID.
+func (r *OrgPromotionResource) exportSources(ctx context.Context, m OrgPromotionResourceModel) ([]string, map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var ids []string
+	diags.Append(m.MetadataIds.ElementsAs(ctx, &ids, false)...)
+	sort.Strings(ids)
+
+	client, d := r.orgClient(m.SourceOrg.ValueString(), path.Root("source_org"))
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+
+	var metadata []models.ExportMetadataTypeInput
+	for _, id := range ids {
+		metadata = append(metadata, models.ExportMetadataTypeInput{Identifier: id})
+	}
+
+	c, err := client.ExportMetadataTML(models.ExportMetadataTMLRequest{
+		Metadata:   metadata,
+		EdocFormat: "YAML",
+		ExportOptions: models.ExportOptions{
+			IncludeGuid: true,
+		},
+	})
+	if err != nil {
+		diags.AddError(
+			"Error Reading Metadata",
+			"Could not export metadata from org '"+m.SourceOrg.ValueString()+"': "+err.Error(),
+		)
+		return nil, nil, diags
+	}
+
+	tmls := map[string]string{}
+	for _, metadata := range c {
+		if metadata.Info.Status.StatusCode == "ERROR" {
+			diags.AddAttributeError(
+				path.Root("metadata_ids"),
+				"Error Reading Metadata",
+				"Could not export '"+metadata.Info.Id+"' from org '"+m.SourceOrg.ValueString()+"': "+metadata.Info.Status.ErrorMessage,
+			)
+			continue
+		}
+		tmls[metadata.Info.Id] = metadata.Edoc
+	}
+
+	// Objects which failed to export were reported above
+	failed := diags.HasError()
+	for _, id := range ids {
+		if _, ok := tmls[id]; !ok && !failed {
+			diags.AddAttributeError(
+				path.Root("metadata_ids"),
+				"Error Reading Metadata",
+				"Object '"+id+"' not found in org '"+m.SourceOrg.ValueString()+"'.",
+			)
+		}
+	}
+
+	return ids, tmls, diags
+}
+
+// sourceHashes hashes the exported TML of every object.
+func sourceHashes(ctx context.Context, tmls map[string]string) (types.Map, diag.Diagnostics) {
+	hashes := map[string]string{}
+	for id, tml := range tmls {
+		hashes[id] = hashTml(tml)
+	}
+
+	return types.MapValueFrom(ctx, types.StringType, hashes)
+}
+
+// promote imports the objects into the target org. Objects already promoted,
+// per existing, are updated in place and the other objects are created. Guids
+// of the source org are rewritten to the guids in the target org, and
+// connections are retargeted as configured for the target.
+func (r *OrgPromotionResource) promote(ctx context.Context, ids []string, sources map[string]string, org string, target OrgPromotionTargetModel, existing map[string]string) (map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	p := path.Root("targets").AtMapKey(org)
+
+	client, d := r.orgClient(org, p)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	connections := map[string]string{}
+	if !target.Connections.IsNull() {
+		diags.Append(target.Connections.ElementsAs(ctx, &connections, false)...)
+	}
+
+	guids := map[string]string{}
+	for id, guid := range existing {
+		guids[id] = guid
+	}
+
+	tmls := map[string]string{}
+	for _, id := range ids {
+		tml, err := retargetTmlConnections(sources[id], connections, "YAML")
+		if err != nil {
+			diags.AddAttributeError(
+				p.AtName("connections"),
+				"Error promoting Metadata",
+				"Could not retarget the connections of '"+id+"': "+err.Error(),
+			)
+			return nil, diags
+		}
+		tmls[id] = tml
+	}
+
+	// New objects are created first, so updated objects can refer to them
+	var created, updated []string
+	for _, id := range ids {
+		if guids[id] == "" {
+			created = append(created, id)
+		} else {
+			updated = append(updated, id)
+		}
+	}
+
+	for _, group := range []struct {
+		ids       []string
+		createNew bool
+	}{{created, true}, {updated, false}} {
+		if len(group.ids) == 0 {
+			continue
+		}
+
+		var oldnew []string
+		for id, guid := range guids {
+			oldnew = append(oldnew, id, guid)
+		}
+		replacer := strings.NewReplacer(oldnew...)
+
+		var refs []tmlObjectRefs
+		var groupTmls []string
+		for _, id := range group.ids {
+			// New objects keep their source guid, so references to them are
+			// rewritten by importMetadataStaged once they are created
+			tml := replacer.Replace(tmls[id])
+			groupTmls = append(groupTmls, tml)
+
+			o, err := parseTmlRefs(tml)
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("metadata_ids"),
+					"Error promoting Metadata",
+					"Could not parse TML of '"+id+"': "+err.Error(),
+				)
+				return nil, diags
+			}
+			refs = append(refs, o)
+		}
+
+		stages, cycle, _ := stageTmlObjects(refs)
+		if len(cycle) > 0 {
+			var cycleIds []string
+			for _, i := range cycle {
+				cycleIds = append(cycleIds, group.ids[i])
+			}
+			diags.AddAttributeError(
+				path.Root("metadata_ids"),
+				"Dependency cycle in metadata",
+				"The objects "+strings.Join(cycleIds, ", ")+" can't be ordered for import because their references form a cycle.",
+			)
+			return nil, diags
+		}
+
+		// Objects imported before a failing stage are returned too, so they
+		// are kept in state
+		imported, d := importMetadataStaged(client, group.ids, groupTmls, stages, group.createNew, "ALL_OR_NONE", "YAML", func(string) path.Path { return p })
+		diags.Append(d...)
+
+		for i, id := range group.ids {
+			if imported[i] != "" {
+				guids[id] = imported[i]
+			}
+		}
+		if diags.HasError() {
+			return guids, diags
+		}
+	}
+
+	return guids, diags
+}
+
+// deleteTargets deletes promoted objects from a target org.
+func (r *OrgPromotionResource) deleteTargets(org string, guids []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if len(guids) == 0 {
+		return diags
+	}
+
+	client, d := r.orgClient(org, path.Root("targets").AtMapKey(org))
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+
+	var metadata []models.DeleteMetadataTypeInput
+	for _, guid := range guids {
+		metadata = append(metadata, models.DeleteMetadataTypeInput{Identifier: guid})
+	}
+
+	err := client.DeleteMetadata(models.DeleteMetadataRequest{
+		Metadata: metadata,
+	})
+	if err != nil {
+		diags.AddError(
+			"Error deleting Metadata",
+			"Could not delete promoted metadata from org '"+org+"', unexpected error: "+err.Error(),
+		)
+	}
+
+	return diags
+}
+
+// targetGuids returns the guids of the promoted objects per target org.
+func targetGuids(ctx context.Context, targets types.Map) (map[string]OrgPromotionTargetModel, map[string]map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	targetModels := map[string]OrgPromotionTargetModel{}
+	guids := map[string]map[string]string{}
+	if targets.IsNull() || targets.IsUnknown() {
+		return targetModels, guids, diags
+	}
+
+	diags.Append(targets.ElementsAs(ctx, &targetModels, false)...)
+	for org, target := range targetModels {
+		guids[org] = map[string]string{}
+		if !target.Guids.IsNull() && !target.Guids.IsUnknown() {
+			m := map[string]string{}
+			diags.Append(target.Guids.ElementsAs(ctx, &m, false)...)
+			guids[org] = m
+		}
+	}
+
+	return targetModels, guids, diags
+}
+
+// setTargetGuids sets the guids of every target org on the model.
+func setTargetGuids(ctx context.Context, m *OrgPromotionResourceModel, targets map[string]OrgPromotionTargetModel, guids map[string]map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	for org, target := range targets {
+		g := guids[org]
+		if g == nil {
+			g = map[string]string{}
+		}
+
+		v, d := types.MapValueFrom(ctx, types.StringType, g)
+		diags.Append(d...)
+		target.Guids = v
+		targets[org] = target
+	}
+
+	v, d := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: OrgPromotionTargetModel{}.attrTypes()}, targets)
+	diags.Append(d...)
+	m.Targets = v
+
+	return diags
+}
+
+// ModifyPlan promotes the objects again when their TML changed in the source
+// org, or when promoted objects were deleted from a target org.
+func (r *OrgPromotionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to check on create and destroy, or before the provider is configured
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() || r.data == nil {
+		return
+	}
+
+	var plan, state OrgPromotionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.MetadataIds.IsUnknown() || plan.Targets.IsUnknown() || plan.SourceOrg.IsUnknown() {
+		return
+	}
+
+	ids, sources, diags := r.exportSources(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	hashes, diags := sourceHashes(ctx, sources)
+	resp.Diagnostics.Append(diags...)
+
+	changed := !hashes.Equal(state.SourceHashes)
+
+	targets, guids, diags := targetGuids(ctx, state.Targets)
+	resp.Diagnostics.Append(diags...)
+	for org := range targets {
+		for _, id := range ids {
+			if guids[org][id] == "" {
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	planTargets := map[string]OrgPromotionTargetModel{}
+	resp.Diagnostics.Append(plan.Targets.ElementsAs(ctx, &planTargets, false)...)
+	for org, target := range planTargets {
+		target.Guids = types.MapUnknown(types.StringType)
+		planTargets[org] = target
+	}
+
+	m, diags := types.MapValueFrom(ctx, types.ObjectType{AttrTypes: OrgPromotionTargetModel{}.attrTypes()}, planTargets)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Targets = m
+	plan.SourceHashes = types.MapUnknown(types.StringType)
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// Create a new resource.
+func (r *OrgPromotionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan OrgPromotionResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, sources, diags := r.exportSources(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targets, _, diags := targetGuids(ctx, plan.Targets)
+	resp.Diagnostics.Append(diags...)
+
+	orgs := make([]string, 0, len(targets))
+	for org := range targets {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	// Objects promoted before an error are kept in state, so they are
+	// deleted when the tainted resource is replaced
+	guids := map[string]map[string]string{}
+	for _, org := range orgs {
+		g, diags := r.promote(ctx, ids, sources, org, targets[org], nil)
+		resp.Diagnostics.Append(diags...)
+		guids[org] = g
+		if resp.Diagnostics.HasError() {
+			break
+		}
+	}
+
+	plan.ID = plan.SourceOrg
+	plan.SourceHashes, diags = sourceHashes(ctx, sources)
+	resp.Diagnostics.Append(diags...)
+
+	diags = setTargetGuids(ctx, &plan, targets, guids)
+	resp.Diagnostics.Append(diags...)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *OrgPromotionResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state OrgPromotionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targets, guids, diags := targetGuids(ctx, state.Targets)
+	resp.Diagnostics.Append(diags...)
+
+	// Promoted objects deleted from a target org are dropped, so they are
+	// promoted again on the next apply
+	for org := range targets {
+		if len(guids[org]) == 0 {
+			continue
+		}
+
+		client, diags := r.orgClient(org, path.Root("targets").AtMapKey(org))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var metadata []models.MetadataListItemInput
+		for _, guid := range guids[org] {
+			metadata = append(metadata, models.MetadataListItemInput{Identifier: guid})
+		}
+
+		c, err := client.SearchMetadata(models.SearchMetadataRequest{
+			Metadata: metadata,
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Metadata",
+				"Could not read promoted metadata in org '"+org+"': "+err.Error(),
+			)
+			return
+		}
+
+		found := map[string]bool{}
+		for _, metadata := range c {
+			found[metadata.MetadataId] = true
+		}
+
+		for id, guid := range guids[org] {
+			if !found[guid] {
+				delete(guids[org], id)
+			}
+		}
+	}
+
+	diags = setTargetGuids(ctx, &state, targets, guids)
+	resp.Diagnostics.Append(diags...)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgPromotionResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan OrgPromotionResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get current state
+	var state OrgPromotionResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, sources, diags := r.exportSources(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	targets, _, diags := targetGuids(ctx, plan.Targets)
+	resp.Diagnostics.Append(diags...)
+
+	stateTargets, stateGuids, diags := targetGuids(ctx, state.Targets)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orgs := make([]string, 0, len(targets))
+	for org := range targets {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	// Like in Create, objects promoted before an error are kept in state.
+	// Objects which weren't deleted stay in state too, so the next apply
+	// deletes them.
+	guids := map[string]map[string]string{}
+	for _, org := range orgs {
+		// Orgs after a failed one aren't promoted
+		if resp.Diagnostics.HasError() {
+			guids[org] = stateGuids[org]
+			continue
+		}
+
+		existing := map[string]string{}
+		var removed []string
+		for id, guid := range stateGuids[org] {
+			if _, ok := sources[id]; ok {
+				existing[id] = guid
+			} else {
+				removed = append(removed, guid)
+			}
+		}
+
+		g, diags := r.promote(ctx, ids, sources, org, targets[org], existing)
+		resp.Diagnostics.Append(diags...)
+		if g == nil {
+			g = existing
+		}
+		guids[org] = g
+
+		// Delete objects which are no longer promoted, unless the promotion
+		// failed
+		if !resp.Diagnostics.HasError() {
+			diags = r.deleteTargets(org, removed)
+			resp.Diagnostics.Append(diags...)
+		}
+		if resp.Diagnostics.HasError() {
+			for id, guid := range stateGuids[org] {
+				if _, ok := sources[id]; !ok {
+					g[id] = guid
+				}
+			}
+		}
+	}
+
+	// Delete objects from orgs which are no longer targets
+	for org, target := range stateTargets {
+		if _, ok := targets[org]; ok {
+			continue
+		}
+
+		if !resp.Diagnostics.HasError() {
+			var removed []string
+			for _, guid := range stateGuids[org] {
+				removed = append(removed, guid)
+			}
+
+			diags = r.deleteTargets(org, removed)
+			resp.Diagnostics.Append(diags...)
+			if !diags.HasError() {
+				continue
+			}
+		}
+
+		targets[org] = target
+		guids[org] = stateGuids[org]
+	}
+
+	plan.ID = plan.SourceOrg
+	plan.SourceHashes, diags = sourceHashes(ctx, sources)
+	resp.Diagnostics.Append(diags...)
+
+	// The sources are promoted again on the next apply
+	if resp.Diagnostics.HasError() {
+		plan.SourceHashes = state.SourceHashes
+	}
+
+	diags = setTargetGuids(ctx, &plan, targets, guids)
+	resp.Diagnostics.Append(diags...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *OrgPromotionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state OrgPromotionResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, guids, diags := targetGuids(ctx, state.Targets)
+	resp.Diagnostics.Append(diags...)
+
+	for org := range guids {
+		var promoted []string
+		for _, guid := range guids[org] {
+			promoted = append(promoted, guid)
+		}
+
+		diags = r.deleteTargets(org, promoted)
+		resp.Diagnostics.Append(diags...)
+	}
+}
+
+// func (r *OrgPromotionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+// 	// Retrieve import ID and save to id attribute
+// 	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+// }
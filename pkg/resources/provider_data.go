@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"sync"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ProviderData is passed by the provider to the resources, holding the client
+// and the provider-wide defaults.
+type ProviderData struct {
+	Client *thoughtspot.Client
+	Host   string
+
+	// Credentials and org of Client, to create clients for other orgs
+	Username      string
+	Password      string
+	OrgIdentifier string
+
+	// Defaults for resources which don't set the attributes themselves
+	DeletionProtection bool
+	RetainOnDestroy    bool
+	GuidMappingFile    string
+
+	orgClientsMu sync.Mutex
+	orgClients   map[string]*thoughtspot.Client
+}
+
+// orgClient returns a client for the given org, signed in with the
+// credentials of the provider. Clients are reused across resources.
+func (d *ProviderData) orgClient(org string) (*thoughtspot.Client, error) {
+	if org == d.OrgIdentifier {
+		return d.Client, nil
+	}
+
+	d.orgClientsMu.Lock()
+	defer d.orgClientsMu.Unlock()
+
+	if client, ok := d.orgClients[org]; ok {
+		return client, nil
+	}
+
+	client, err := thoughtspot.NewClient(&d.Host, &d.Username, &d.Password, &org)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.orgClients == nil {
+		d.orgClients = map[string]*thoughtspot.Client{}
+	}
+	d.orgClients[org] = client
+
+	return client, nil
+}
+
+// primaryOrg is the identifier of the primary org. Orgs can only be created,
+// changed and deleted, and their members managed, from the primary org.
+const primaryOrg = "0"
+
+// primaryOrgClient returns a client for the primary org, signed in with the
+// credentials of the provider whichever org the provider is scoped to.
+func (d *ProviderData) primaryOrgClient() (*thoughtspot.Client, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	client, err := d.orgClient(primaryOrg)
+	if err != nil {
+		diags.AddError(
+			"Unable to Thoughtspot Client",
+			"Could not sign in to the primary org, which manages orgs: "+err.Error(),
+		)
+	}
+
+	return client, diags
+}
+
+// boolOrDefault returns the value of an optional attribute, or the provider
+// default when it isn't set.
+func boolOrDefault(v types.Bool, def bool) bool {
+	if v.IsNull() || v.IsUnknown() {
+		return def
+	}
+
+	return v.ValueBool()
+}
+
+// deleteOnDestroy reports whether Delete should delete the object on the
+// server. With retain_on_destroy the object is only removed from state, while
+// deletion protection fails the destroy with an error.
+func (d *ProviderData) deleteOnDestroy(deletionProtection types.Bool, retainOnDestroy types.Bool, object string, diags *diag.Diagnostics) bool {
+	var protect, retain bool
+	if d != nil {
+		protect, retain = d.DeletionProtection, d.RetainOnDestroy
+	}
+
+	if boolOrDefault(retainOnDestroy, retain) {
+		return false
+	}
+
+	if boolOrDefault(deletionProtection, protect) {
+		diags.AddError(
+			"Deletion protection enabled",
+			"Can't delete "+object+" because deletion protection is enabled. "+
+				"Set deletion_protection to false and apply first, or set retain_on_destroy to keep the object on the server.",
+		)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// rawStateUpgrader returns a state upgrader which rewrites the JSON of the
+// prior state. It suits changes which don't need the prior schema to read the
+// state, such as lists becoming sets or attributes being renamed or removed.
+// Upgraders which need typed values should set PriorSchema instead, as
+// MetadataResource does.
+func rawStateUpgrader(upgrade func(state map[string]any) error) resource.StateUpgrader {
+	return resource.StateUpgrader{
+		StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+			if req.RawState == nil || req.RawState.JSON == nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade Resource State",
+					"The prior state is missing or not in JSON format. Please report this issue to the provider developers.",
+				)
+				return
+			}
+
+			var state map[string]any
+			if err := json.Unmarshal(req.RawState.JSON, &state); err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade Resource State",
+					"Could not read the prior state, unexpected error: "+err.Error(),
+				)
+				return
+			}
+
+			if err := upgrade(state); err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade Resource State",
+					"Could not upgrade the prior state, unexpected error: "+err.Error(),
+				)
+				return
+			}
+
+			b, err := json.Marshal(state)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to Upgrade Resource State",
+					"Could not write the upgraded state, unexpected error: "+err.Error(),
+				)
+				return
+			}
+
+			resp.DynamicValue = &tfprotov6.DynamicValue{JSON: b}
+		},
+	}
+}
+
+// uniqueStateList removes duplicate values from a list in raw state, so it can
+// be read as a set.
+func uniqueStateList(state map[string]any, key string) {
+	values, ok := state[key].([]any)
+	if !ok {
+		return
+	}
+
+	seen := map[string]bool{}
+	unique := []any{}
+	for _, v := range values {
+		b, _ := json.Marshal(v)
+		if seen[string(b)] {
+			continue
+		}
+		seen[string(b)] = true
+		unique = append(unique, v)
+	}
+
+	state[key] = unique
+}
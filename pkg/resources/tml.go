@@ -3,12 +3,13 @@ package resources
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/daniepett/thoughtspot-sdk-go"
 	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
@@ -18,14 +19,18 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &TmlResource{}
-	_ resource.ResourceWithConfigure = &TmlResource{}
+	_ resource.Resource               = &TmlResource{}
+	_ resource.ResourceWithConfigure  = &TmlResource{}
+	_ resource.ResourceWithModifyPlan = &TmlResource{}
+	_ resource.ResourceWithMoveState  = &TmlResource{}
 	// _ resource.ResourceWithImportState = &TmlResource{}
 )
 
@@ -35,15 +40,51 @@ func NewTmlResource() resource.Resource {
 
 type TmlResource struct {
 	client *thoughtspot.Client
+	data   *ProviderData
 }
 
 // orderResourceModel maps the resource schema data.
 type TmlResourceModel struct {
 	ID          types.String `tfsdk:"id"`
 	Tml         types.String `tfsdk:"tml"`
+	TmlFile     types.String `tfsdk:"tml_file"`
+	TmlHash     types.String `tfsdk:"tml_hash"`
+	TmlSections types.Map    `tfsdk:"tml_sections"`
+	Variables   types.Map    `tfsdk:"variables"`
+	References  types.Map    `tfsdk:"references"`
+	Format      types.String `tfsdk:"format"`
+	Type        types.String `tfsdk:"type"`
 	Guids       types.List   `tfsdk:"guids"`
 	UseObjectId types.Bool   `tfsdk:"use_object_id"`
 	Name        types.String `tfsdk:"name"`
+
+	MetadataType types.String `tfsdk:"metadata_type"`
+	Author       types.String `tfsdk:"author"`
+	Owner        types.String `tfsdk:"owner"`
+	Created      types.String `tfsdk:"created"`
+	Modified     types.String `tfsdk:"modified"`
+	Tags         types.Set    `tfsdk:"tags"`
+	Url          types.String `tfsdk:"url"`
+	Share        types.Set    `tfsdk:"share"`
+
+	DeletionProtection types.Bool `tfsdk:"deletion_protection"`
+	RetainOnDestroy    types.Bool `tfsdk:"retain_on_destroy"`
+
+	GuidMappingFile types.String `tfsdk:"guid_mapping_file"`
+}
+
+type TmlShareModel struct {
+	Principal types.String `tfsdk:"principal"`
+	Type      types.String `tfsdk:"type"`
+	Mode      types.String `tfsdk:"mode"`
+}
+
+func (o TmlShareModel) attrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"principal": types.StringType,
+		"type":      types.StringType,
+		"mode":      types.StringType,
+	}
 }
 
 type TmlGuidModel struct {
@@ -81,16 +122,16 @@ func (m requiresReplaceIfGuidChangedModifier) PlanModifyString(ctx context.Conte
 		return
 	}
 
+	currentGuid := tmlGuid(req.StateValue.ValueString())
+	newGuid := tmlGuid(req.ConfigValue.ValueString())
+
 	// If we don't have a guid in the state or config, then don't evaluate
-	if !strings.HasPrefix(req.StateValue.String(), "guid:") || !strings.HasPrefix(req.ConfigValue.String(), "guid:") {
+	if currentGuid == "" || newGuid == "" {
 		return
 	}
 
-	re := regexp.MustCompile(`guid: ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-	currentGuids := re.FindAllStringSubmatch(req.StateValue.String(), -1)
-	newGuids := re.FindAllStringSubmatch(req.ConfigValue.String(), -1)
-	// Checks the first guid value hasn't changed
-	if currentGuids[0][1] != newGuids[0][1] {
+	// Checks the top-level guid value hasn't changed
+	if currentGuid != newGuid {
 		resp.RequiresReplace = true
 	}
 }
@@ -105,11 +146,8 @@ func (r *TmlResource) ValidateConfig(ctx context.Context, req resource.ValidateC
 	}
 
 	if config.UseObjectId.ValueBool() {
-		tml := config.Tml.ValueString()
-
-		// Check for GUIDs in the TML string
-		guidRegex := regexp.MustCompile(`^guid: ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-		if guidRegex.MatchString(tml) {
+		// Check for a GUID in the TML string
+		if tmlGuid(config.Tml.ValueString()) != "" {
 			resp.Diagnostics.AddAttributeError(
 				path.Root("tml"),
 				"GUIDs Not Allowed When Using Object ID",
@@ -117,6 +155,102 @@ func (r *TmlResource) ValidateConfig(ctx context.Context, req resource.ValidateC
 			)
 		}
 	}
+
+	if config.Tml.IsNull() || config.Tml.IsUnknown() {
+		return
+	}
+
+	_, diags = validateTmlType(config.Tml.ValueString(), path.Root("tml"))
+	resp.Diagnostics.Append(diags...)
+
+	if config.References.IsNull() || config.References.IsUnknown() {
+		return
+	}
+
+	// Only the keys are needed to check they match a table in the TML
+	var refs map[string]types.String
+	diags = config.References.ElementsAs(ctx, &refs, false)
+	resp.Diagnostics.Append(diags...)
+
+	references := map[string]string{}
+	for name := range refs {
+		references[name] = ""
+	}
+
+	_, diags = injectReferences(config.Tml.ValueString(), references, config.Format.ValueString())
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *TmlResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan TmlResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var state TmlResourceModel
+	if !req.State.Raw.IsNull() {
+		diags = req.State.Get(ctx, &state)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	var tml string
+	switch {
+	case plan.TmlFile.IsUnknown():
+		// The file content is read during apply if the path isn't known yet
+		return
+	case plan.TmlFile.IsNull():
+		plan.TmlHash = types.StringNull()
+		plan.TmlSections = types.MapNull(types.StringType)
+
+		if plan.Tml.IsUnknown() {
+			diags = resp.Plan.Set(ctx, plan)
+			resp.Diagnostics.Append(diags...)
+			return
+		}
+		tml = plan.Tml.ValueString()
+	default:
+		tml, diags = tmlFileHashes(ctx, &plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		var guids []MetadataGuidModel
+		if !state.Guids.IsNull() {
+			diags = state.Guids.ElementsAs(ctx, &guids, false)
+			resp.Diagnostics.Append(diags...)
+		}
+
+		// Same as requiresReplaceIfGuidChanged, a new top-level guid is a different object
+		newGuid := tmlGuid(tml)
+		if len(guids) > 0 && newGuid != "" && guids[0].Original.ValueString() != newGuid {
+			resp.RequiresReplace = append(resp.RequiresReplace, path.Root("tml_file"))
+		}
+	}
+
+	objectType, diags := validateTmlType(tml, path.Root("tml_file"))
+	// Inline TML is already validated in ValidateConfig
+	if !plan.TmlFile.IsNull() {
+		resp.Diagnostics.Append(diags...)
+	}
+
+	plan.Type = types.StringValue(objectType)
+	if !state.Type.IsNull() && !state.Type.Equal(plan.Type) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("type"))
+	}
+
+	diags = resp.Plan.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
 }
 
 // Metadata returns the resource type name.
@@ -127,6 +261,7 @@ func (r *TmlResource) Metadata(_ context.Context, req resource.MetadataRequest,
 // Schema defines the schema for the resource.
 func (r *TmlResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 0,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -140,11 +275,92 @@ func (r *TmlResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"type": schema.StringAttribute{
+				Computed:    true,
+				Description: "Object type of the TML, for example `table`, `worksheet`, `model`, `sql_view`, `answer` or `liveboard`.",
+			},
+			"metadata_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "Metadata type of the object, for example `LOGICAL_TABLE`, `ANSWER` or `LIVEBOARD`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"author": schema.StringAttribute{
+				Computed:    true,
+				Description: "Name of the user who created the object.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"owner": schema.StringAttribute{
+				Computed:    true,
+				Description: "GUID of the owner of the object.",
+			},
+			"created": schema.StringAttribute{
+				Computed:    true,
+				Description: "Creation time of the object, in RFC 3339 format.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"modified": schema.StringAttribute{
+				Computed:    true,
+				Description: "Last modification time of the object, in RFC 3339 format.",
+			},
+			"tags": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Description: "Names of the tags assigned to the object. When set, tags are assigned and unassigned to match.",
+			},
+			"url": schema.StringAttribute{
+				Computed:    true,
+				Description: "Link to the object in ThoughtSpot.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"tml": schema.StringAttribute{
-				Required: true,
+				Optional: true,
 				PlanModifiers: []planmodifier.String{
 					requiresReplaceIfGuidChanged(),
 				},
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("tml_file")),
+				},
+			},
+			"tml_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a TML file to import. Only a hash of the content is stored in state.",
+			},
+			"tml_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 hash of the content of `tml_file`.",
+			},
+			"tml_sections": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Short hash per top-level section of `tml_file`, showing which sections changed in the plan.",
+			},
+			"variables": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Values for `${name}` placeholders in the TML, substituted before import and restored on export. Escape placeholders as `$${name}` in inline HCL strings.",
+			},
+			"format": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Format of the TML. Accepts `YAML`, `JSON`",
+				Default:     stringdefault.StaticString("YAML"),
+				Validators: []validator.String{
+					stringvalidator.OneOf(tmlFormats...),
+				},
+			},
+			"references": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Map of table `id` or `name` in the TML to the GUID of the object it refers to, injected as `fqn` before import. Referencing another resource's `id` makes it a dependency.",
 			},
 			"guids": schema.ListNestedAttribute{
 				NestedObject: schema.NestedAttributeObject{
@@ -168,6 +384,18 @@ func (r *TmlResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 					listplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"deletion_protection": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Fail on destroy instead of deleting the object. Defaults to the provider's `deletion_protection`.",
+			},
+			"retain_on_destroy": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only remove the object from state on destroy, keeping it on the server. Defaults to the provider's `retain_on_destroy`.",
+			},
+			"guid_mapping_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path of a GUID mapping file in the ThoughtSpot deploy format. GUIDs of the TML are replaced with the mapped GUIDs before import, and the GUIDs of the imported object are added to the file. Defaults to the provider's `guid_mapping_file`.",
+			},
 			"use_object_id": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -178,7 +406,134 @@ func (r *TmlResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *
 				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"share": schema.SetNestedBlock{
+				Description: "Shares the object with a user or group.",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"principal": schema.StringAttribute{
+							Required:    true,
+							Description: "Unique ID or name of the user or group.",
+						},
+						"type": schema.StringAttribute{
+							Required:    true,
+							Description: "Principal type. Accepts `USER`, `USER_GROUP`",
+							Validators: []validator.String{
+								stringvalidator.OneOf("USER", "USER_GROUP"),
+							},
+						},
+						"mode": schema.StringAttribute{
+							Optional:    true,
+							Description: "Type of access to the object. Accepts `READ_ONLY`, `MODIFY`. Defaults to `READ_ONLY`",
+							Validators: []validator.String{
+								stringvalidator.OneOf("READ_ONLY", "MODIFY"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// MoveState moves a thoughtspot_metadata package into thoughtspot_tml, keeping
+// the object on the server. A moved block moves the whole resource, so only
+// packages with a single object can be moved.
+func (r *TmlResource) MoveState(ctx context.Context) []resource.StateMover {
+	var source resource.SchemaResponse
+	NewMetadataResource().Schema(ctx, resource.SchemaRequest{}, &source)
+
+	return []resource.StateMover{
+		{
+			SourceSchema: &source.Schema,
+			StateMover:   moveMetadataState,
+		},
+	}
+}
+
+func moveMetadataState(ctx context.Context, req resource.MoveStateRequest, resp *resource.MoveStateResponse) {
+	if req.SourceTypeName != "thoughtspot_metadata" || !strings.HasSuffix(req.SourceProviderAddress, "/thoughtspot") {
+		return
+	}
+
+	if req.SourceSchemaVersion != 1 || req.SourceState == nil {
+		resp.Diagnostics.AddError(
+			"Unable to Move Resource State",
+			fmt.Sprintf("Can't move thoughtspot_metadata state of schema version %d. Apply with the current provider version first, so the state is upgraded, and then move it.", req.SourceSchemaVersion),
+		)
+		return
+	}
+
+	var source MetadataResourceModel
+	resp.Diagnostics.Append(req.SourceState.Get(ctx, &source)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys, objects, diags := metadataObjects(ctx, source.Objects)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(objects) != 1 {
+		resp.Diagnostics.AddError(
+			"Unable to Move Resource State",
+			fmt.Sprintf("A moved block moves the whole thoughtspot_metadata resource, so only packages with a single object can be moved into thoughtspot_tml. This package has %d objects: %s. ", len(objects), strings.Join(keys, ", "))+
+				"Remove the other objects from the package first with on_remove = \"retain\", and manage them with thoughtspot_tml resources whose TML keeps the guid of the object, which updates the existing objects instead of creating new ones.",
+		)
+		return
 	}
+
+	object := objects[0]
+	if object.ID.IsNull() {
+		resp.Diagnostics.AddError(
+			"Unable to Move Resource State",
+			"The object '"+keys[0]+"' wasn't imported yet, so there is nothing to move. Apply the package first.",
+		)
+		return
+	}
+
+	variables, diags := tmlVariables(ctx, source.Variables)
+	resp.Diagnostics.Append(diags...)
+
+	objectType, _, err := tmlObjectType(substituteTmlVariables(object.Tml.ValueString(), variables))
+	if err != nil || objectType == "" {
+		resp.Diagnostics.AddError(
+			"Unable to Move Resource State",
+			"Could not detect the object type of '"+keys[0]+"'.",
+		)
+		return
+	}
+
+	// Server-side attributes are filled in by the refresh after the move
+	target := TmlResourceModel{
+		ID:                 object.ID,
+		Tml:                object.Tml,
+		TmlFile:            types.StringNull(),
+		TmlHash:            types.StringNull(),
+		TmlSections:        types.MapNull(types.StringType),
+		Variables:          source.Variables,
+		References:         types.MapNull(types.StringType),
+		Format:             source.Format,
+		Type:               types.StringValue(objectType),
+		Guids:              object.Guids,
+		UseObjectId:        types.BoolValue(false),
+		Name:               object.Name,
+		MetadataType:       types.StringValue(tmlMetadataTypes[objectType]),
+		Author:             types.StringNull(),
+		Owner:              types.StringNull(),
+		Created:            types.StringNull(),
+		Modified:           types.StringNull(),
+		Tags:               types.SetNull(types.StringType),
+		Url:                types.StringNull(),
+		Share:              types.SetValueMust(types.ObjectType{AttrTypes: TmlShareModel{}.attrTypes()}, []attr.Value{}),
+		DeletionProtection: types.BoolNull(),
+		RetainOnDestroy:    types.BoolNull(),
+		GuidMappingFile:    source.GuidMappingFile,
+	}
+
+	resp.Diagnostics.Append(resp.TargetState.Set(ctx, target)...)
 }
 
 // Configure adds the provider configured client to the resource.
@@ -187,28 +542,161 @@ func (r *TmlResource) Configure(_ context.Context, req resource.ConfigureRequest
 		return
 	}
 
-	client, ok := req.ProviderData.(*thoughtspot.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *qlikcloud.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
+	r.data = data
 }
 
-func exportTml(ctx context.Context, client *thoughtspot.Client, id string, tml string, existingGuids []MetadataGuidModel, useObjectId bool) (*TmlResourceModel, diag.Diagnostics) {
+// tmlFileHashes reads tml_file and sets tml_hash and tml_sections on the model.
+func tmlFileHashes(ctx context.Context, m *TmlResourceModel) (string, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
+	tml, err := readTmlFile(m.TmlFile.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("tml_file"),
+			"Error reading TML file",
+			"Could not read TML file "+m.TmlFile.ValueString()+": "+err.Error(),
+		)
+		return "", diags
+	}
+
+	sections, err := tmlSectionHashes(tml)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("tml_file"),
+			"Error parsing TML file",
+			"Could not parse TML file "+m.TmlFile.ValueString()+": "+err.Error(),
+		)
+		return "", diags
+	}
+
+	m.TmlHash = types.StringValue(hashTml(tml))
+	m.TmlSections, diags = types.MapValueFrom(ctx, types.StringType, sections)
+
+	return tml, diags
+}
+
+// tmlContent returns the TML to import, reading tml_file if set. The file
+// must still match the hash computed when the plan was made.
+func tmlContent(ctx context.Context, plan *TmlResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if plan.TmlFile.IsNull() {
+		return plan.Tml.ValueString(), diags
+	}
+
+	plannedHash := plan.TmlHash
+	tml, diags := tmlFileHashes(ctx, plan)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	if !plannedHash.IsUnknown() && !plannedHash.Equal(plan.TmlHash) {
+		diags.AddAttributeError(
+			path.Root("tml_file"),
+			"TML file changed after plan",
+			"The content of "+plan.TmlFile.ValueString()+" changed after the plan was created. Run the plan again.",
+		)
+		return "", diags
+	}
+
+	return tml, diags
+}
+
+// validateTmlType detects the object type of the TML and checks it defines
+// the fields that type requires.
+func validateTmlType(tml string, p path.Path) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	objectType, missing, err := tmlObjectType(tml)
+	if err != nil {
+		diags.AddAttributeError(
+			p,
+			"Error parsing TML",
+			"Could not parse TML, unexpected error: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	if objectType == "" {
+		diags.AddAttributeError(
+			p,
+			"Unsupported TML type",
+			"The TML must define one of table, worksheet, model, view, sql_view, answer, liveboard or connection.",
+		)
+		return "", diags
+	}
+
+	for _, field := range missing {
+		diags.AddAttributeError(
+			p,
+			"Invalid "+objectType+" TML",
+			"A "+objectType+" TML must define '"+field+"'.",
+		)
+	}
+
+	return objectType, diags
+}
+
+// tmlReferences converts the references attribute into a plain map.
+func tmlReferences(ctx context.Context, v types.Map) (map[string]string, diag.Diagnostics) {
+	references := map[string]string{}
+	if v.IsNull() || v.IsUnknown() {
+		return references, nil
+	}
+
+	diags := v.ElementsAs(ctx, &references, false)
+
+	return references, diags
+}
+
+// injectReferences sets the fqn of every referenced table in the TML.
+func injectReferences(tml string, references map[string]string, format string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	out, unmatched, err := injectTmlReferences(tml, references, format)
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("references"),
+			"Error resolving TML references",
+			"Could not parse TML, unexpected error: "+err.Error(),
+		)
+		return "", diags
+	}
+
+	for _, name := range unmatched {
+		diags.AddAttributeError(
+			path.Root("references").AtMapKey(name),
+			"Unknown TML reference",
+			"No table with id or name '"+name+"' found in the TML.",
+		)
+	}
+
+	return out, diags
+}
+
+func exportTml(ctx context.Context, client *thoughtspot.Client, id string, objectType string, tml string, format string, variables map[string]string, existingGuids []MetadataGuidModel, useObjectId bool) (*TmlResourceModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	rendered := substituteTmlVariables(tml, variables)
+
 	cr := models.ExportMetadataTMLRequest{
 		Metadata: []models.ExportMetadataTypeInput{models.ExportMetadataTypeInput{
 			Identifier: id,
+			Type:       tmlMetadataTypes[objectType],
 		}},
-		EdocFormat: "YAML",
+		EdocFormat: format,
 		ExportOptions: models.ExportOptions{
 			IncludeGuid:  !useObjectId,
 			IncludeObjId: useObjectId,
@@ -238,44 +726,49 @@ func exportTml(ctx context.Context, client *thoughtspot.Client, id string, tml s
 
 	metadata := c[0]
 
-	tmlExport := metadata.Edoc
+	pairs, err := mapTmlGuids(rendered, metadata.Edoc)
+	if err != nil {
+		diags.AddError(
+			"Error reading TML",
+			"Could not parse exported tml, unexpected error: "+err.Error(),
+		)
+		return nil, diags
+	}
+
 	var guids []MetadataGuidModel
+	for _, p := range pairs {
+		guids = append(guids, MetadataGuidModel{
+			Original: types.StringValue(p.Original),
+			Computed: types.StringValue(p.Computed),
+		})
+	}
 
-	re := regexp.MustCompile(`guid: ([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})`)
-	ogids := re.FindAllStringSubmatch(tml, -1)
-	cgids := re.FindAllStringSubmatch(metadata.Edoc, -1)
-	if (len(ogids) == 0 || len(cgids) == 0) && !useObjectId {
+	if len(guids) == 0 && !useObjectId && existingGuids == nil {
 		diags.AddError(
 			"Could not extract guids from TML",
 			"No guids found for Metadata ID: "+id,
 		)
 		return nil, diags
 	}
-	if existingGuids != nil && len(ogids) != len(cgids) {
+	if len(guids) == 0 && existingGuids != nil {
 		guids = existingGuids
-	} else {
-		for j := range ogids {
-			guid := MetadataGuidModel{
-				Original: types.StringValue(ogids[j][1]),
-				Computed: types.StringValue(cgids[j][1]),
-			}
-			guids = append(guids, guid)
-
+		for _, guid := range guids {
+			pairs = append(pairs, tmlGuidPair{
+				Original: guid.Original.ValueString(),
+				Computed: guid.Computed.ValueString(),
+			})
 		}
 	}
 
-	if len(guids) > 0 {
-		for _, guid := range guids {
-			tmlExport = strings.Replace(tml, guid.Computed.ValueString(), guid.Original.ValueString(), 1)
-		}
-	} else {
+	tmlExport := templateTml(restoreTmlGuids(metadata.Edoc, pairs), rendered, tml, variables, format)
+
+	if len(guids) == 0 {
 		// Computed attribute can't be nil
 		guid := MetadataGuidModel{
 			Original: types.StringValue(id),
 			Computed: types.StringValue(id),
 		}
 		guids = append(guids, guid)
-
 	}
 
 	lg, diag := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: MetadataGuidModel{}.attrTypes()}, guids)
@@ -292,6 +785,340 @@ func exportTml(ctx context.Context, client *thoughtspot.Client, id string, tml s
 	return &m, diags
 }
 
+// tmlUrlPaths are the paths of the links to objects per metadata type.
+var tmlUrlPaths = map[string]string{
+	"LIVEBOARD":     "pinboard",
+	"ANSWER":        "saved-answer",
+	"LOGICAL_TABLE": "data/tables",
+	"CONNECTION":    "data/embrace/connection",
+}
+
+// tmlUrl returns the link to the object on the host.
+func tmlUrl(host string, metadataType string, id string) string {
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	p, ok := tmlUrlPaths[metadataType]
+	if !ok {
+		p = "pinboard"
+	}
+
+	return strings.TrimSuffix(host, "/") + "/#/" + p + "/" + id
+}
+
+// formatTmlTime formats a header timestamp in epoch milliseconds.
+func formatTmlTime(ms float64) types.String {
+	if ms == 0 {
+		return types.StringNull()
+	}
+
+	return types.StringValue(time.UnixMilli(int64(ms)).UTC().Format(time.RFC3339))
+}
+
+// refreshHeader sets the computed attributes from the metadata header.
+func (r *TmlResource) refreshHeader(ctx context.Context, m *TmlResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	metadataType := tmlMetadataTypes[m.Type.ValueString()]
+
+	host := ""
+	if r.data != nil {
+		host = r.data.Host
+	}
+
+	c, err := r.client.SearchMetadata(models.SearchMetadataRequest{
+		Metadata: []models.MetadataListItemInput{{
+			Identifier: m.ID.ValueString(),
+			Type:       metadataType,
+		}},
+	})
+	if err != nil {
+		// Planned and prior values are kept, only the unknown ones are
+		// cleared, so the attributes are known even without a header
+		if m.MetadataType.IsUnknown() {
+			m.MetadataType = types.StringValue(metadataType)
+		}
+		for _, v := range []*types.String{&m.Author, &m.Owner, &m.Created, &m.Modified} {
+			if v.IsUnknown() {
+				*v = types.StringNull()
+			}
+		}
+		if m.Tags.IsUnknown() {
+			m.Tags = types.SetNull(types.StringType)
+		}
+		if m.Url.IsUnknown() {
+			m.Url = types.StringValue(tmlUrl(host, m.MetadataType.ValueString(), m.ID.ValueString()))
+		}
+
+		diags.AddError(
+			"Error reading TML",
+			"Could not read metadata header of "+m.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	m.MetadataType = types.StringValue(metadataType)
+	m.Author = types.StringNull()
+	m.Owner = types.StringNull()
+	m.Created = types.StringNull()
+	m.Modified = types.StringNull()
+	m.Tags = types.SetNull(types.StringType)
+
+	if len(c) > 0 {
+		header := c[0].MetadataHeader
+		if c[0].MetadataType != "" {
+			m.MetadataType = types.StringValue(c[0].MetadataType)
+		}
+		m.Author = types.StringValue(header.AuthorName)
+		m.Owner = types.StringValue(header.Owner)
+		m.Created = formatTmlTime(header.Created)
+		m.Modified = formatTmlTime(header.Modified)
+
+		tags := []string{}
+		for _, tag := range header.Tags {
+			tags = append(tags, tag.Name)
+		}
+
+		st, d := types.SetValueFrom(ctx, types.StringType, tags)
+		diags.Append(d...)
+		m.Tags = st
+	}
+
+	m.Url = types.StringValue(tmlUrl(host, m.MetadataType.ValueString(), m.ID.ValueString()))
+
+	return diags
+}
+
+// applyTags assigns the planned tags which aren't assigned yet and unassigns
+// the ones which were removed. Unset tags are left alone.
+func (r *TmlResource) applyTags(ctx context.Context, id string, metadataType string, plan types.Set, state types.Set) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if plan.IsNull() || plan.IsUnknown() {
+		return diags
+	}
+
+	var planned, current []string
+	diags.Append(plan.ElementsAs(ctx, &planned, false)...)
+	if !state.IsNull() && !state.IsUnknown() {
+		diags.Append(state.ElementsAs(ctx, &current, false)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	metadata := []models.TagMetadataTypeInput{{
+		Identifier: id,
+		Type:       metadataType,
+	}}
+
+	var assigned, unassigned []string
+	for _, tag := range planned {
+		if !slices.Contains(current, tag) {
+			assigned = append(assigned, tag)
+		}
+	}
+	for _, tag := range current {
+		if !slices.Contains(planned, tag) {
+			unassigned = append(unassigned, tag)
+		}
+	}
+
+	if len(assigned) > 0 {
+		err := r.client.AssignTag(models.AssignTagRequest{
+			Metadata:       metadata,
+			TagIdentifiers: assigned,
+		})
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("tags"),
+				"Error assigning tags",
+				"Could not assign tags, unexpected error: "+err.Error(),
+			)
+		}
+	}
+
+	if len(unassigned) > 0 {
+		err := r.client.UnassignTag(models.UnassignTagRequest{
+			Metadata:       metadata,
+			TagIdentifiers: unassigned,
+		})
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("tags"),
+				"Error unassigning tags",
+				"Could not unassign tags, unexpected error: "+err.Error(),
+			)
+		}
+	}
+
+	return diags
+}
+
+// applyShares shares the object with the planned principals and revokes the
+// access of principals which were removed.
+func (r *TmlResource) applyShares(ctx context.Context, id string, metadataType string, plan types.Set, state types.Set) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if plan.Equal(state) {
+		return diags
+	}
+
+	var planned, current []TmlShareModel
+	if !plan.IsNull() {
+		diags.Append(plan.ElementsAs(ctx, &planned, false)...)
+	}
+	if !state.IsNull() && !state.IsUnknown() {
+		diags.Append(state.ElementsAs(ctx, &current, false)...)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	var u []models.SharePermissionsInput
+	for _, share := range planned {
+		mode := share.Mode.ValueString()
+		if mode == "" {
+			mode = "READ_ONLY"
+		}
+
+		u = append(u, models.SharePermissionsInput{
+			Principal: models.PrincipalsInput{
+				Identifier: share.Principal.ValueString(),
+				Type:       share.Type.ValueString(),
+			},
+			ShareMode: mode,
+		})
+	}
+
+	for _, share := range current {
+		removed := !slices.ContainsFunc(planned, func(p TmlShareModel) bool {
+			return p.Principal.Equal(share.Principal) && p.Type.Equal(share.Type)
+		})
+		if removed {
+			u = append(u, models.SharePermissionsInput{
+				Principal: models.PrincipalsInput{
+					Identifier: share.Principal.ValueString(),
+					Type:       share.Type.ValueString(),
+				},
+				ShareMode: "NO_ACCESS",
+			})
+		}
+	}
+
+	if len(u) == 0 {
+		return diags
+	}
+
+	err := r.client.ShareMetadata(models.ShareMetadataRequest{
+		MetadataType:        metadataType,
+		MetadataIdentifiers: []string{id},
+		Permissions:         u,
+	})
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("share"),
+			"Error sharing TML",
+			"Could not share the object, unexpected error: "+err.Error(),
+		)
+	}
+
+	return diags
+}
+
+// readShares drops the shares whose principal lost access to the object and
+// refreshes the mode of the others.
+func (r *TmlResource) readShares(ctx context.Context, m *TmlResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.Share.IsNull() || m.Share.IsUnknown() {
+		return diags
+	}
+
+	var shares []TmlShareModel
+	diags.Append(m.Share.ElementsAs(ctx, &shares, false)...)
+	if diags.HasError() || len(shares) == 0 {
+		return diags
+	}
+
+	var principals []models.PrincipalsInput
+	for _, share := range shares {
+		principals = append(principals, models.PrincipalsInput{
+			Identifier: share.Principal.ValueString(),
+			Type:       share.Type.ValueString(),
+		})
+	}
+
+	c, err := r.client.FetchPermissionsOnMetadata(models.FetchPermissionsOnMetadataRequest{
+		Metadata: []models.PermissionsMetadataTypeInput{{
+			Identifier: m.ID.ValueString(),
+			Type:       m.MetadataType.ValueString(),
+		}},
+		Principals: principals,
+	})
+	if err != nil {
+		diags.AddError(
+			"Error reading TML shares",
+			"Could not read permissions on "+m.ID.ValueString()+", unexpected error: "+err.Error(),
+		)
+		return diags
+	}
+
+	// Permission per principal type and id or name
+	permissions := map[string]string{}
+	if c != nil {
+		for _, metadata := range c.MetadataPermissionDetails {
+			for _, info := range metadata.PrincipalPermissionInfo {
+				for _, p := range info.PrincipalPermissions {
+					permissions[info.PrincipalType+"|"+p.PrincipalId] = p.Permission
+					permissions[info.PrincipalType+"|"+p.PrincipalName] = p.Permission
+				}
+			}
+		}
+	}
+
+	var refreshed []TmlShareModel
+	for _, share := range shares {
+		permission, ok := permissions[share.Type.ValueString()+"|"+share.Principal.ValueString()]
+		if !ok || permission == "NO_ACCESS" {
+			continue
+		}
+
+		if !(share.Mode.IsNull() && permission == "READ_ONLY") {
+			share.Mode = types.StringValue(permission)
+		}
+		refreshed = append(refreshed, share)
+	}
+
+	st, d := types.SetValueFrom(ctx, types.ObjectType{AttrTypes: TmlShareModel{}.attrTypes()}, refreshed)
+	diags.Append(d...)
+	m.Share = st
+
+	return diags
+}
+
+// recordGuidMapping adds the guids of the imported object to the mapping file.
+func (r *TmlResource) recordGuidMapping(ctx context.Context, file string, guids types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if file == "" {
+		return diags
+	}
+
+	pairs, d := guidMappingPairs(ctx, guids)
+	diags.Append(d...)
+
+	if err := recordGuidMapping(file, pairs); err != nil {
+		diags.AddAttributeWarning(
+			path.Root("guid_mapping_file"),
+			"Error updating GUID mapping file",
+			"Could not write the GUIDs of the imported object to "+file+": "+err.Error(),
+		)
+	}
+
+	return diags
+}
+
 // Create a new resource.
 func (r *TmlResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	// Retrieve values from plan
@@ -302,8 +1129,46 @@ func (r *TmlResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
+	tml, diags := tmlContent(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objectType, diags := validateTmlType(tml, path.Root("tml"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Type = types.StringValue(objectType)
+
+	variables, diags := tmlVariables(ctx, plan.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	references, diags := tmlReferences(ctx, plan.References)
+	resp.Diagnostics.Append(diags...)
+
+	payload, diags := injectReferences(substituteTmlVariables(tml, variables), references, plan.Format.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	mappingFile := r.data.guidMappingFile(plan.GuidMappingFile)
+	payload, err := applyGuidMapping(payload, mappingFile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error applying GUID mapping",
+			"Could not map the GUIDs of the TML with "+mappingFile+": "+err.Error(),
+		)
+		return
+	}
+
 	cr := models.ImportMetadataTMLRequest{
-		MetadataTmls: []string{plan.Tml.ValueString()},
+		MetadataTmls: []string{payload},
 		ImportPolicy: "ALL_OR_NONE",
 		CreateNew:    false,
 	}
@@ -330,7 +1195,7 @@ func (r *TmlResource) Create(ctx context.Context, req resource.CreateRequest, re
 	// Sleep for a few seconds to allow the resource to be ready
 	time.Sleep(5 * time.Second)
 
-	ex, diags := exportTml(ctx, r.client, id, plan.Tml.ValueString(), nil, plan.UseObjectId.ValueBool())
+	ex, diags := exportTml(ctx, r.client, id, plan.Type.ValueString(), tml, plan.Format.ValueString(), variables, nil, plan.UseObjectId.ValueBool())
 	if diags.HasError() {
 		resp.Diagnostics.Append(diags...)
 		return
@@ -343,6 +1208,28 @@ func (r *TmlResource) Create(ctx context.Context, req resource.CreateRequest, re
 	// plan.Tml = ex.Tml
 	plan.Guids = ex.Guids
 
+	diags = r.recordGuidMapping(ctx, mappingFile, plan.Guids)
+	resp.Diagnostics.Append(diags...)
+
+	metadataType := tmlMetadataTypes[plan.Type.ValueString()]
+
+	diags = r.applyTags(ctx, id, metadataType, plan.Tags, types.SetNull(types.StringType))
+	resp.Diagnostics.Append(diags...)
+
+	// Shares which failed are left out of state, so the next apply retries
+	// them. Tags are read back from the header below.
+	diags = r.applyShares(ctx, id, metadataType, plan.Share, types.SetNull(types.ObjectType{AttrTypes: TmlShareModel{}.attrTypes()}))
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		plan.Share = types.SetNull(types.ObjectType{AttrTypes: TmlShareModel{}.attrTypes()})
+	}
+
+	// The object is imported at this point, so a header which can't be read
+	// doesn't fail the apply. Read fills it in on the next refresh.
+	for _, d := range r.refreshHeader(ctx, &plan) {
+		resp.Diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+
 	// Set state to fully populated data
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -366,7 +1253,11 @@ func (r *TmlResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	if guids == nil {
 		guids = []MetadataGuidModel{}
 	}
-	ex, diags := exportTml(ctx, r.client, state.ID.ValueString(), state.Tml.ValueString(), guids, state.UseObjectId.ValueBool())
+
+	variables, diags := tmlVariables(ctx, state.Variables)
+	resp.Diagnostics.Append(diags...)
+
+	ex, diags := exportTml(ctx, r.client, state.ID.ValueString(), state.Type.ValueString(), state.Tml.ValueString(), state.Format.ValueString(), variables, guids, state.UseObjectId.ValueBool())
 	if diags.HasError() {
 		resp.Diagnostics.Append(diags...)
 		return
@@ -377,10 +1268,25 @@ func (r *TmlResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	state.Tml = ex.Tml
+	// State only holds the hash when the TML comes from a file
+	if state.TmlFile.IsNull() {
+		state.Tml = ex.Tml
+	}
 	state.Guids = ex.Guids
 	state.Name = ex.Name
 
+	diags = r.refreshHeader(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = r.readShares(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// Set refreshed state
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -399,14 +1305,58 @@ func (r *TmlResource) Update(ctx context.Context, req resource.UpdateRequest, re
 		return
 	}
 
-	tml := plan.Tml.ValueString()
+	tml, diags := tmlContent(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	objectType, diags := validateTmlType(tml, path.Root("tml"))
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Type = types.StringValue(objectType)
+
+	variables, diags := tmlVariables(ctx, plan.Variables)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	references, diags := tmlReferences(ctx, plan.References)
+	resp.Diagnostics.Append(diags...)
+
+	tml, diags = injectReferences(substituteTmlVariables(tml, variables), references, plan.Format.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	var guids []MetadataGuidModel
 	diags = plan.Guids.ElementsAs(ctx, &guids, false)
 	resp.Diagnostics.Append(diags...)
+	computed := map[string]string{}
 	for _, guid := range guids {
-		tml = strings.Replace(tml, guid.Original.ValueString(), guid.Computed.ValueString(), 1)
+		computed[guid.Original.ValueString()] = guid.Computed.ValueString()
+	}
 
+	tml, err := replaceTmlGuids(tml, computed, plan.Format.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error importing TML",
+			"Could not replace the GUIDs of the TML, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	mappingFile := r.data.guidMappingFile(plan.GuidMappingFile)
+	tml, err = applyGuidMapping(tml, mappingFile)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error applying GUID mapping",
+			"Could not map the GUIDs of the TML with "+mappingFile+": "+err.Error(),
+		)
+		return
 	}
 
 	cr := models.ImportMetadataTMLRequest{
@@ -441,6 +1391,35 @@ func (r *TmlResource) Update(ctx context.Context, req resource.UpdateRequest, re
 
 	plan.Name = types.StringValue(c[0].Response.Header.Name)
 
+	diags = r.recordGuidMapping(ctx, mappingFile, plan.Guids)
+	resp.Diagnostics.Append(diags...)
+
+	var state TmlResourceModel
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadataType := tmlMetadataTypes[plan.Type.ValueString()]
+
+	diags = r.applyTags(ctx, plan.ID.ValueString(), metadataType, plan.Tags, state.Tags)
+	resp.Diagnostics.Append(diags...)
+
+	// Shares which failed are left out of state, so the next apply retries
+	// them. Tags are read back from the header below.
+	diags = r.applyShares(ctx, plan.ID.ValueString(), metadataType, plan.Share, state.Share)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		plan.Share = state.Share
+	}
+
+	// The object is imported at this point, so a header which can't be read
+	// doesn't fail the apply. Read fills it in on the next refresh.
+	for _, d := range r.refreshHeader(ctx, &plan) {
+		resp.Diagnostics.AddWarning(d.Summary(), d.Detail())
+	}
+
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -457,8 +1436,15 @@ func (r *TmlResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 		return
 	}
 
+	if !r.data.deleteOnDestroy(state.DeletionProtection, state.RetainOnDestroy, "TML object '"+state.Name.ValueString()+"'", &resp.Diagnostics) {
+		return
+	}
+
 	cr := models.DeleteMetadataRequest{
-		Metadata: []models.DeleteMetadataTypeInput{{Identifier: state.ID.ValueString()}},
+		Metadata: []models.DeleteMetadataTypeInput{{
+			Identifier: state.ID.ValueString(),
+			Type:       tmlMetadataTypes[state.Type.ValueString()],
+		}},
 	}
 
 	err := r.client.DeleteMetadata(cr)
@@ -0,0 +1,915 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"gopkg.in/yaml.v3"
+)
+
+// tmlFormats are the TML encodings supported for import and export.
+var tmlFormats = []string{"YAML", "JSON"}
+
+// tmlGuidFields are the fields holding the guid or object id of the object,
+// of its visualizations and of the objects it refers to.
+var tmlGuidFields = []string{"guid", "viz_guid", "fqn", "obj_id"}
+
+// tmlGuidPair is a guid of the original TML and the guid the server assigned
+// at the same path.
+type tmlGuidPair struct {
+	Path     string
+	Original string
+	Computed string
+}
+
+// tmlGuidValue is a guid field and its path in the document.
+type tmlGuidValue struct {
+	Path  string
+	Value string
+}
+
+// tmlGuid returns the top-level guid of the TML, or "" if it has none.
+func tmlGuid(tml string) string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return ""
+	}
+
+	if v := tmlMappingValue(doc.Content[0], "guid"); v != nil {
+		return v.Value
+	}
+
+	return ""
+}
+
+// tmlGuidPaths returns the guid fields of the TML in document order, with
+// their path such as `liveboard.visualizations[Viz_1].viz_guid`. List items
+// are identified by their id or name, so reordering them keeps the paths.
+func tmlGuidPaths(tml string) ([]tmlGuidValue, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return nil, err
+	}
+
+	var values []tmlGuidValue
+	seen := map[string]bool{}
+
+	var walk func(node *yaml.Node, p string)
+	walk = func(node *yaml.Node, p string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, c := range node.Content {
+				walk(c, p)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i].Value, node.Content[i+1]
+				kp := key
+				if p != "" {
+					kp = p + "." + key
+				}
+
+				if value.Kind == yaml.ScalarNode && slices.Contains(tmlGuidFields, key) {
+					values = append(values, tmlGuidValue{Path: kp, Value: value.Value})
+					continue
+				}
+
+				walk(value, kp)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				id := strconv.Itoa(i)
+				if item.Kind == yaml.MappingNode {
+					for _, key := range []string{"id", "name"} {
+						if v := tmlMappingValue(item, key); v != nil && v.Kind == yaml.ScalarNode && v.Value != "" {
+							id = v.Value
+							break
+						}
+					}
+				}
+
+				// Fall back to the index for items sharing an id
+				ip := fmt.Sprintf("%s[%s]", p, id)
+				if seen[ip] {
+					ip = fmt.Sprintf("%s[%d]", p, i)
+				}
+				seen[ip] = true
+
+				walk(item, ip)
+			}
+		}
+	}
+	walk(&doc, "")
+
+	return values, nil
+}
+
+// mapTmlGuids pairs the guids of the original TML with the guids of the
+// exported TML by their path, in the order of the original TML. Guids at
+// paths which only exist in one of them are left out.
+func mapTmlGuids(original string, exported string) ([]tmlGuidPair, error) {
+	ovalues, err := tmlGuidPaths(original)
+	if err != nil {
+		return nil, err
+	}
+
+	cvalues, err := tmlGuidPaths(exported)
+	if err != nil {
+		return nil, err
+	}
+
+	computed := map[string]string{}
+	for _, v := range cvalues {
+		computed[v.Path] = v.Value
+	}
+
+	var pairs []tmlGuidPair
+	for _, v := range ovalues {
+		if c, ok := computed[v.Path]; ok {
+			pairs = append(pairs, tmlGuidPair{Path: v.Path, Original: v.Value, Computed: c})
+		}
+	}
+
+	return pairs, nil
+}
+
+// restoreTmlGuids replaces the computed guids in the exported TML with the
+// original ones, all at once so swapped guids don't replace each other.
+func restoreTmlGuids(exported string, pairs []tmlGuidPair) string {
+	var oldnew []string
+	for _, p := range pairs {
+		if p.Computed != p.Original {
+			oldnew = append(oldnew, p.Computed, p.Original)
+		}
+	}
+
+	if len(oldnew) == 0 {
+		return exported
+	}
+
+	return strings.NewReplacer(oldnew...).Replace(exported)
+}
+
+// replaceTmlGuids sets the guid fields of the TML whose value is a key of
+// guids to the mapped guid. Text elsewhere in the TML which happens to equal
+// a guid is left alone.
+func replaceTmlGuids(tml string, guids map[string]string, format string) (string, error) {
+	if len(guids) == 0 {
+		return tml, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return "", err
+	}
+
+	replaced := false
+	walkTmlNodes(&doc, func(key string, value *yaml.Node) {
+		if value.Kind != yaml.ScalarNode || !slices.Contains(tmlGuidFields, key) {
+			return
+		}
+		if guid, ok := guids[value.Value]; ok && guid != value.Value {
+			value.Value = guid
+			replaced = true
+		}
+	})
+
+	if !replaced {
+		return tml, nil
+	}
+
+	return marshalTml(&doc, format)
+}
+
+// readTmlFile reads a TML file from disk and returns its content.
+func readTmlFile(filePath string) (string, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// hashTml returns the sha256 hex digest of the TML content.
+func hashTml(tml string) string {
+	sum := sha256.Sum256([]byte(tml))
+	return hex.EncodeToString(sum[:])
+}
+
+// tmlSectionHashes returns a short hash per top-level TML section, so a
+// changed file only shows the sections that differ in the plan.
+// The object body (e.g. `liveboard`) is split one level further into
+// `liveboard.visualizations`, `liveboard.layout` and so on.
+func tmlSectionHashes(tml string) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return nil, err
+	}
+
+	sections := map[string]string{}
+	if len(doc.Content) == 0 {
+		return sections, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping at the top level of the TML")
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+		value := root.Content[i+1]
+
+		if value.Kind != yaml.MappingNode {
+			h, err := hashTmlNode(value)
+			if err != nil {
+				return nil, err
+			}
+			sections[key] = h
+			continue
+		}
+
+		for j := 0; j+1 < len(value.Content); j += 2 {
+			h, err := hashTmlNode(value.Content[j+1])
+			if err != nil {
+				return nil, err
+			}
+			sections[key+"."+value.Content[j].Value] = h
+		}
+	}
+
+	return sections, nil
+}
+
+func hashTmlNode(node *yaml.Node) (string, error) {
+	b, err := yaml.Marshal(node)
+	if err != nil {
+		return "", err
+	}
+
+	return hashTml(string(b))[:12], nil
+}
+
+// tmlVariables converts the variables attribute into a plain map.
+func tmlVariables(ctx context.Context, v types.Map) (map[string]string, diag.Diagnostics) {
+	variables := map[string]string{}
+	if v.IsNull() || v.IsUnknown() {
+		return variables, nil
+	}
+
+	diags := v.ElementsAs(ctx, &variables, false)
+
+	return variables, diags
+}
+
+// substituteTmlVariables replaces `${name}` placeholders with their values.
+func substituteTmlVariables(tml string, variables map[string]string) string {
+	for name, value := range variables {
+		tml = strings.ReplaceAll(tml, "${"+name+"}", value)
+	}
+
+	return tml
+}
+
+// restoreTmlVariables puts the `${name}` placeholders back into exported TML.
+// Only the values at the places where the template has a placeholder are
+// restored, and only if they still render to the same text, so unrelated
+// text which happens to equal a value is left alone.
+func restoreTmlVariables(exported string, template string, variables map[string]string, format string) string {
+	if len(variables) == 0 || !strings.Contains(template, "${") {
+		return exported
+	}
+
+	var edoc, tdoc yaml.Node
+	if yaml.Unmarshal([]byte(exported), &edoc) != nil || yaml.Unmarshal([]byte(template), &tdoc) != nil {
+		return exported
+	}
+
+	if !restoreTmlNodeVariables(&edoc, &tdoc, variables) {
+		return exported
+	}
+
+	out, err := marshalTml(&edoc, format)
+	if err != nil {
+		return exported
+	}
+
+	return out
+}
+
+// restoreTmlNodeVariables walks the exported and the template document side
+// by side, matching mappings by key and sequences by index, and restores
+// the template text of scalars holding placeholders. It reports whether
+// anything was restored.
+func restoreTmlNodeVariables(exported *yaml.Node, template *yaml.Node, variables map[string]string) bool {
+	restored := false
+
+	switch {
+	case template.Kind == yaml.ScalarNode && exported.Kind == yaml.ScalarNode:
+		if strings.Contains(template.Value, "${") && template.Value != exported.Value &&
+			substituteTmlVariables(template.Value, variables) == exported.Value {
+			exported.Value = template.Value
+			exported.Tag = "!!str"
+			restored = true
+		}
+	case template.Kind != exported.Kind:
+	case template.Kind == yaml.DocumentNode, template.Kind == yaml.SequenceNode:
+		for i := 0; i < len(template.Content) && i < len(exported.Content); i++ {
+			restored = restoreTmlNodeVariables(exported.Content[i], template.Content[i], variables) || restored
+		}
+	case template.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(template.Content); i += 2 {
+			key := template.Content[i]
+			rendered := substituteTmlVariables(key.Value, variables)
+
+			for j := 0; j+1 < len(exported.Content); j += 2 {
+				if exported.Content[j].Value != rendered {
+					continue
+				}
+				restored = restoreTmlNodeVariables(exported.Content[j], key, variables) || restored
+				restored = restoreTmlNodeVariables(exported.Content[j+1], template.Content[i+1], variables) || restored
+				break
+			}
+		}
+	}
+
+	return restored
+}
+
+// templateTml maps exported TML back onto the template it was rendered from.
+// If the export still has every configured field the template is kept as
+// written, otherwise the export is returned with its placeholders restored.
+func templateTml(exported string, rendered string, template string, variables map[string]string, format string) string {
+	if exported == rendered || tmlCovers(exported, rendered) {
+		return template
+	}
+
+	return restoreTmlVariables(exported, template, variables, format)
+}
+
+// tmlCovers reports whether the exported TML keeps every field set in the
+// configured TML, ignoring guid fields. Fields the server adds to the export,
+// like defaults, aren't a change of the object.
+func tmlCovers(exported string, configured string) bool {
+	var x, y any
+	if yaml.Unmarshal([]byte(exported), &x) != nil || yaml.Unmarshal([]byte(configured), &y) != nil {
+		return false
+	}
+
+	return tmlValueCovers(x, y)
+}
+
+// tmlValueCovers compares decoded TML values for tmlCovers. Lists must have
+// the same length, their elements are compared in order.
+func tmlValueCovers(exported any, configured any) bool {
+	switch c := configured.(type) {
+	case map[string]any:
+		e, ok := exported.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range c {
+			if slices.Contains(tmlGuidFields, k) {
+				continue
+			}
+			ev, ok := e[k]
+			if !ok {
+				if v == nil {
+					continue
+				}
+				return false
+			}
+			if !tmlValueCovers(ev, v) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		e, ok := exported.([]any)
+		if !ok || len(e) != len(c) {
+			return false
+		}
+		for i := range c {
+			if !tmlValueCovers(e[i], c[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(exported, configured)
+}
+
+// tmlTableKeys are the TML keys that hold lists of table references.
+var tmlTableKeys = map[string]bool{
+	"tables":       true,
+	"model_tables": true,
+}
+
+// injectTmlReferences sets `fqn` on every table reference whose `id` or
+// `name` is a key in references. It returns the reference keys that matched
+// no table, so a typo doesn't silently fall back to a lookup by name.
+func injectTmlReferences(tml string, references map[string]string, format string) (string, []string, error) {
+	if len(references) == 0 {
+		return tml, nil, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return "", nil, err
+	}
+
+	matched := map[string]bool{}
+	walkTmlNodes(&doc, func(key string, value *yaml.Node) {
+		if !tmlTableKeys[key] || value.Kind != yaml.SequenceNode {
+			return
+		}
+
+		for _, table := range value.Content {
+			if table.Kind != yaml.MappingNode {
+				continue
+			}
+
+			for _, field := range []string{"id", "name"} {
+				ref := tmlMappingValue(table, field)
+				if ref == nil {
+					continue
+				}
+				if guid, ok := references[ref.Value]; ok {
+					setTmlMappingValue(table, "fqn", guid)
+					matched[ref.Value] = true
+					break
+				}
+			}
+		}
+	})
+
+	var unmatched []string
+	for name := range references {
+		if !matched[name] {
+			unmatched = append(unmatched, name)
+		}
+	}
+	sort.Strings(unmatched)
+
+	out, err := marshalTml(&doc, format)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return out, unmatched, nil
+}
+
+// walkTmlNodes calls fn for every key/value pair of every mapping in the
+// document, depth first.
+func walkTmlNodes(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkTmlNodes(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(node.Content[i].Value, node.Content[i+1])
+			walkTmlNodes(node.Content[i+1], fn)
+		}
+	}
+}
+
+// tmlMappingValue returns the value node for key in a mapping node.
+func tmlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// setTmlMappingValue sets a scalar value in a mapping node, adding the key
+// if it isn't there yet.
+func setTmlMappingValue(node *yaml.Node, key string, value string) {
+	if v := tmlMappingValue(node, key); v != nil {
+		v.Kind = yaml.ScalarNode
+		v.Tag = "!!str"
+		v.Value = value
+		v.Content = nil
+		return
+	}
+
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+	)
+}
+
+// marshalTml encodes a TML document in the given format, with the two space
+// indent ThoughtSpot uses.
+func marshalTml(node *yaml.Node, format string) (string, error) {
+	if format == "JSON" {
+		var b bytes.Buffer
+		if err := encodeTmlJSON(&b, node); err != nil {
+			return "", err
+		}
+
+		var out bytes.Buffer
+		if err := json.Indent(&out, b.Bytes(), "", "  "); err != nil {
+			return "", err
+		}
+
+		return out.String(), nil
+	}
+
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// encodeTmlJSON writes a TML document as JSON. Unlike going through
+// map[string]interface{}, this keeps the key order of the document.
+func encodeTmlJSON(b *bytes.Buffer, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			b.WriteString("null")
+			return nil
+		}
+		return encodeTmlJSON(b, node.Content[0])
+	case yaml.AliasNode:
+		return encodeTmlJSON(b, node.Alias)
+	case yaml.MappingNode:
+		b.WriteString("{")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			k, err := json.Marshal(node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			b.Write(k)
+			b.WriteString(":")
+			if err := encodeTmlJSON(b, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+	case yaml.SequenceNode:
+		b.WriteString("[")
+		for i, child := range node.Content {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			if err := encodeTmlJSON(b, child); err != nil {
+				return err
+			}
+		}
+		b.WriteString("]")
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			b.WriteString(node.Value)
+		case "!!bool":
+			b.WriteString(strings.ToLower(node.Value))
+		case "!!null":
+			b.WriteString("null")
+		default:
+			v, err := json.Marshal(node.Value)
+			if err != nil {
+				return err
+			}
+			b.Write(v)
+		}
+	}
+
+	return nil
+}
+
+// tmlMetadataTypes maps the TML object type, the top-level key holding the
+// object, to the metadata type used by the REST API.
+var tmlMetadataTypes = map[string]string{
+	"table":      "LOGICAL_TABLE",
+	"worksheet":  "LOGICAL_TABLE",
+	"model":      "LOGICAL_TABLE",
+	"view":       "LOGICAL_TABLE",
+	"sql_view":   "LOGICAL_TABLE",
+	"answer":     "ANSWER",
+	"liveboard":  "LIVEBOARD",
+	"pinboard":   "LIVEBOARD",
+	"connection": "CONNECTION",
+}
+
+// tmlRequiredFields lists the fields an object type must define to import.
+var tmlRequiredFields = map[string][]string{
+	"table":     {"connection", "db_table"},
+	"sql_view":  {"connection", "sql_query"},
+	"worksheet": {"tables"},
+	"model":     {"model_tables"},
+}
+
+// tmlObjectType returns the object type of the TML along with any fields
+// that type requires but the TML doesn't define.
+func tmlObjectType(tml string) (string, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return "", nil, err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", nil, nil
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		objectType := root.Content[i].Value
+		if _, ok := tmlMetadataTypes[objectType]; !ok {
+			continue
+		}
+
+		var missing []string
+		for _, field := range tmlRequiredFields[objectType] {
+			if v := tmlMappingValue(root.Content[i+1], field); v == nil || (v.Kind == yaml.ScalarNode && v.Value == "") {
+				missing = append(missing, field)
+			}
+		}
+
+		return objectType, missing, nil
+	}
+
+	return "", nil, nil
+}
+
+// tmlObjectRefs describes the object a TML defines and the objects it
+// refers to.
+type tmlObjectRefs struct {
+	Guid string
+	Name string
+	Refs []tmlRef
+}
+
+// tmlRef is a reference from a TML to another object, by name, by fqn or
+// both.
+type tmlRef struct {
+	Name string
+	Fqn  string
+	Type string
+}
+
+// parseTmlRefs reads the guid and name of the object a TML defines, and the
+// tables, worksheets, models and connections it refers to.
+func parseTmlRefs(tml string) (tmlObjectRefs, error) {
+	var o tmlObjectRefs
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return o, err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return o, nil
+	}
+
+	root := doc.Content[0]
+	if v := tmlMappingValue(root, "guid"); v != nil {
+		o.Guid = v.Value
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if _, ok := tmlMetadataTypes[root.Content[i].Value]; !ok {
+			continue
+		}
+
+		body := root.Content[i+1]
+		if v := tmlMappingValue(body, "name"); v != nil {
+			o.Name = v.Value
+		}
+
+		walkTmlNodes(body, func(key string, value *yaml.Node) {
+			switch {
+			case tmlTableKeys[key] && value.Kind == yaml.SequenceNode:
+				for _, table := range value.Content {
+					if ref, ok := tmlRefFrom(table, "LOGICAL_TABLE"); ok {
+						o.Refs = append(o.Refs, ref)
+					}
+				}
+			case key == "connection" && value.Kind == yaml.MappingNode:
+				if ref, ok := tmlRefFrom(value, "CONNECTION"); ok {
+					o.Refs = append(o.Refs, ref)
+				}
+			}
+		})
+		break
+	}
+
+	return o, nil
+}
+
+func tmlRefFrom(node *yaml.Node, metadataType string) (tmlRef, bool) {
+	if node.Kind != yaml.MappingNode {
+		return tmlRef{}, false
+	}
+
+	ref := tmlRef{Type: metadataType}
+	if v := tmlMappingValue(node, "name"); v != nil {
+		ref.Name = v.Value
+	} else if v := tmlMappingValue(node, "id"); v != nil {
+		ref.Name = v.Value
+	}
+	if v := tmlMappingValue(node, "fqn"); v != nil {
+		ref.Fqn = v.Value
+	}
+
+	return ref, ref.Name != "" || ref.Fqn != ""
+}
+
+// stageTmlObjects groups objects into import stages, where every object only
+// depends on objects in earlier stages. If the objects can't be ordered, the
+// indexes left over are returned as the cycle. References to objects outside
+// of the list are returned per object as unresolved.
+func stageTmlObjects(objects []tmlObjectRefs) ([][]int, []int, map[int][]tmlRef) {
+	deps := make([][]int, len(objects))
+	unresolved := map[int][]tmlRef{}
+
+	for i, o := range objects {
+		seen := map[int]bool{}
+		for _, ref := range o.Refs {
+			dep := -1
+			for j, other := range objects {
+				if j == i {
+					continue
+				}
+				if (ref.Fqn != "" && ref.Fqn == other.Guid) || (ref.Name != "" && ref.Name == other.Name) {
+					dep = j
+					break
+				}
+			}
+
+			if dep < 0 {
+				unresolved[i] = append(unresolved[i], ref)
+				continue
+			}
+			if !seen[dep] {
+				deps[i] = append(deps[i], dep)
+				seen[dep] = true
+			}
+		}
+	}
+
+	var stages [][]int
+	done := make([]bool, len(objects))
+	for remaining := len(objects); remaining > 0; {
+		var stage []int
+		for i := range objects {
+			if done[i] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range deps[i] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				stage = append(stage, i)
+			}
+		}
+
+		if len(stage) == 0 {
+			var cycle []int
+			for i := range objects {
+				if !done[i] {
+					cycle = append(cycle, i)
+				}
+			}
+			return stages, cycle, unresolved
+		}
+
+		for _, i := range stage {
+			done[i] = true
+		}
+		remaining -= len(stage)
+		stages = append(stages, stage)
+	}
+
+	return stages, nil, unresolved
+}
+
+// copyTml renames the object a TML defines and, if worksheet is set, points
+// every table reference at it. Unless keepGuid is set the top-level guid is
+// removed, so the import creates a new object instead of updating the source.
+func copyTml(tml string, name string, worksheet string, keepGuid bool, format string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return "", err
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return "", fmt.Errorf("TML doesn't define an object")
+	}
+
+	root := doc.Content[0]
+	if !keepGuid {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value == "guid" {
+				root.Content = append(root.Content[:i], root.Content[i+2:]...)
+				break
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if _, ok := tmlMetadataTypes[root.Content[i].Value]; !ok {
+			continue
+		}
+
+		body := root.Content[i+1]
+		setTmlMappingValue(body, "name", name)
+
+		if worksheet != "" {
+			walkTmlNodes(body, func(key string, value *yaml.Node) {
+				if !tmlTableKeys[key] || value.Kind != yaml.SequenceNode {
+					return
+				}
+
+				for _, table := range value.Content {
+					if table.Kind == yaml.MappingNode {
+						setTmlMappingValue(table, "fqn", worksheet)
+					}
+				}
+			})
+		}
+		break
+	}
+
+	return marshalTml(&doc, format)
+}
+
+// retargetTmlConnections points connection references of the TML from the
+// connections of the source environment to the connections named in
+// connections. The fqn of a retargeted connection is removed, as it is the
+// guid in the source environment.
+func retargetTmlConnections(tml string, connections map[string]string, format string) (string, error) {
+	if len(connections) == 0 {
+		return tml, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(tml), &doc); err != nil {
+		return "", err
+	}
+
+	changed := false
+	walkTmlNodes(&doc, func(key string, value *yaml.Node) {
+		if key != "connection" || value.Kind != yaml.MappingNode {
+			return
+		}
+
+		name := tmlMappingValue(value, "name")
+		if name == nil {
+			return
+		}
+		target, ok := connections[name.Value]
+		if !ok {
+			return
+		}
+
+		name.Value = target
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			if value.Content[i].Value == "fqn" {
+				value.Content = append(value.Content[:i], value.Content[i+2:]...)
+				break
+			}
+		}
+		changed = true
+	})
+
+	if !changed {
+		return tml, nil
+	}
+
+	return marshalTml(&doc, format)
+}
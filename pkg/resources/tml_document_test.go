@@ -0,0 +1,316 @@
+package resources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	testTableTml = `guid: 0a1b2c3d-0000-4000-8000-000000000001
+table:
+  name: Orders
+  connection:
+    name: Warehouse
+`
+	testWorksheetTml = `guid: 0a1b2c3d-0000-4000-8000-000000000002
+worksheet:
+  name: Sales
+  tables:
+  - name: Orders
+    fqn: 0a1b2c3d-0000-4000-8000-000000000001
+  - id: Customers
+`
+	testLiveboardTml = `guid: 0a1b2c3d-0000-4000-8000-000000000003
+liveboard:
+  name: Sales Overview
+  visualizations:
+  - id: Viz_1
+    answer:
+      name: Revenue
+      tables:
+      - id: Sales
+        name: Sales
+`
+)
+
+// TestParseTmlRefs parses the guid, name and references of TML objects.
+func TestParseTmlRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		tml  string
+		want tmlObjectRefs
+	}{
+		{
+			name: "table",
+			tml:  testTableTml,
+			want: tmlObjectRefs{
+				Guid: "0a1b2c3d-0000-4000-8000-000000000001",
+				Name: "Orders",
+				Refs: []tmlRef{{Name: "Warehouse", Type: "CONNECTION"}},
+			},
+		},
+		{
+			name: "worksheet",
+			tml:  testWorksheetTml,
+			want: tmlObjectRefs{
+				Guid: "0a1b2c3d-0000-4000-8000-000000000002",
+				Name: "Sales",
+				Refs: []tmlRef{
+					{Name: "Orders", Fqn: "0a1b2c3d-0000-4000-8000-000000000001", Type: "LOGICAL_TABLE"},
+					{Name: "Customers", Type: "LOGICAL_TABLE"},
+				},
+			},
+		},
+		{
+			name: "liveboard",
+			tml:  testLiveboardTml,
+			want: tmlObjectRefs{
+				Guid: "0a1b2c3d-0000-4000-8000-000000000003",
+				Name: "Sales Overview",
+				Refs: []tmlRef{{Name: "Sales", Type: "LOGICAL_TABLE"}},
+			},
+		},
+		{
+			name: "no object",
+			tml:  "guid: 0a1b2c3d-0000-4000-8000-000000000004\n",
+			want: tmlObjectRefs{Guid: "0a1b2c3d-0000-4000-8000-000000000004"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTmlRefs(tt.tml)
+			if err != nil {
+				t.Fatalf("parsing TML: %s", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseTmlRefs("table: [unclosed"); err == nil {
+		t.Errorf("expected an error for invalid TML")
+	}
+}
+
+// TestStageTmlObjects orders objects into import stages by their references.
+func TestStageTmlObjects(t *testing.T) {
+	parse := func(tmls ...string) []tmlObjectRefs {
+		t.Helper()
+
+		var objects []tmlObjectRefs
+		for _, tml := range tmls {
+			o, err := parseTmlRefs(tml)
+			if err != nil {
+				t.Fatalf("parsing TML: %s", err)
+			}
+			objects = append(objects, o)
+		}
+		return objects
+	}
+
+	t.Run("dependency order", func(t *testing.T) {
+		// Listed in reverse, the liveboard refers to the worksheet by name and
+		// the worksheet to the table by fqn
+		stages, cycle, _ := stageTmlObjects(parse(testLiveboardTml, testWorksheetTml, testTableTml))
+		if len(cycle) > 0 {
+			t.Fatalf("unexpected cycle %v", cycle)
+		}
+
+		want := [][]int{{2}, {1}, {0}}
+		if !reflect.DeepEqual(stages, want) {
+			t.Errorf("got stages %v, want %v", stages, want)
+		}
+	})
+
+	t.Run("independent objects", func(t *testing.T) {
+		stages, cycle, _ := stageTmlObjects(parse(testTableTml, testLiveboardTml))
+		if len(cycle) > 0 {
+			t.Fatalf("unexpected cycle %v", cycle)
+		}
+
+		want := [][]int{{0, 1}}
+		if !reflect.DeepEqual(stages, want) {
+			t.Errorf("got stages %v, want %v", stages, want)
+		}
+	})
+
+	t.Run("out of package references", func(t *testing.T) {
+		_, _, unresolved := stageTmlObjects(parse(testTableTml, testWorksheetTml))
+
+		want := map[int][]tmlRef{
+			0: {{Name: "Warehouse", Type: "CONNECTION"}},
+			1: {{Name: "Customers", Type: "LOGICAL_TABLE"}},
+		}
+		if !reflect.DeepEqual(unresolved, want) {
+			t.Errorf("got unresolved %v, want %v", unresolved, want)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		a := "guid: a\nworksheet:\n  name: A\n  tables:\n  - name: B\n"
+		b := "guid: b\nworksheet:\n  name: B\n  tables:\n  - name: A\n"
+
+		stages, cycle, _ := stageTmlObjects(parse(testTableTml, a, b))
+		if !reflect.DeepEqual(stages, [][]int{{0}}) {
+			t.Errorf("got stages %v before the cycle, want [[0]]", stages)
+		}
+		if !reflect.DeepEqual(cycle, []int{1, 2}) {
+			t.Errorf("got cycle %v, want [1 2]", cycle)
+		}
+	})
+}
+
+const (
+	testOriginalLiveboardTml = `guid: lb-original
+liveboard:
+  name: Sales
+  description: Copied from lb-original
+  visualizations:
+  - id: Viz_1
+    viz_guid: viz-1-original
+    answer:
+      name: Revenue
+  - id: Viz_2
+    viz_guid: viz-2-original
+    answer:
+      name: Orders
+`
+	// The server reordered the visualizations and added one
+	testExportedLiveboardTml = `guid: lb-computed
+liveboard:
+  name: Sales
+  description: Copied from lb-original
+  visualizations:
+  - id: Viz_2
+    viz_guid: viz-2-computed
+    answer:
+      name: Orders
+  - id: Viz_3
+    viz_guid: viz-3-computed
+    answer:
+      name: Margin
+  - id: Viz_1
+    viz_guid: viz-1-computed
+    answer:
+      name: Revenue
+`
+)
+
+// TestTmlGuidPaths identifies list items by id, so reordering them keeps the
+// paths of their guids.
+func TestTmlGuidPaths(t *testing.T) {
+	got, err := tmlGuidPaths(testExportedLiveboardTml)
+	if err != nil {
+		t.Fatalf("reading guid paths: %s", err)
+	}
+
+	want := []tmlGuidValue{
+		{Path: "guid", Value: "lb-computed"},
+		{Path: "liveboard.visualizations[Viz_2].viz_guid", Value: "viz-2-computed"},
+		{Path: "liveboard.visualizations[Viz_3].viz_guid", Value: "viz-3-computed"},
+		{Path: "liveboard.visualizations[Viz_1].viz_guid", Value: "viz-1-computed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Items sharing an id fall back to their index
+	got, err = tmlGuidPaths("worksheet:\n  tables:\n  - name: T\n    fqn: a\n  - name: T\n    fqn: b\n")
+	if err != nil {
+		t.Fatalf("reading guid paths: %s", err)
+	}
+
+	want = []tmlGuidValue{
+		{Path: "worksheet.tables[T].fqn", Value: "a"},
+		{Path: "worksheet.tables[1].fqn", Value: "b"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestMapTmlGuids pairs guids by path in the order of the original TML, and
+// leaves out the visualization only the export has.
+func TestMapTmlGuids(t *testing.T) {
+	got, err := mapTmlGuids(testOriginalLiveboardTml, testExportedLiveboardTml)
+	if err != nil {
+		t.Fatalf("mapping guids: %s", err)
+	}
+
+	want := []tmlGuidPair{
+		{Path: "guid", Original: "lb-original", Computed: "lb-computed"},
+		{Path: "liveboard.visualizations[Viz_1].viz_guid", Original: "viz-1-original", Computed: "viz-1-computed"},
+		{Path: "liveboard.visualizations[Viz_2].viz_guid", Original: "viz-2-original", Computed: "viz-2-computed"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// TestRestoreTmlGuids restores the original guids in the export.
+func TestRestoreTmlGuids(t *testing.T) {
+	pairs, err := mapTmlGuids(testOriginalLiveboardTml, testExportedLiveboardTml)
+	if err != nil {
+		t.Fatalf("mapping guids: %s", err)
+	}
+
+	got := restoreTmlGuids(testExportedLiveboardTml, pairs)
+	want := strings.NewReplacer(
+		"lb-computed", "lb-original",
+		"viz-1-computed", "viz-1-original",
+		"viz-2-computed", "viz-2-original",
+	).Replace(testExportedLiveboardTml)
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Swapped guids don't replace each other
+	swapped := []tmlGuidPair{
+		{Path: "a", Original: "guid-1", Computed: "guid-2"},
+		{Path: "b", Original: "guid-2", Computed: "guid-1"},
+	}
+	if got := restoreTmlGuids("a: guid-2\nb: guid-1\n", swapped); got != "a: guid-1\nb: guid-2\n" {
+		t.Errorf("got %q after restoring swapped guids", got)
+	}
+}
+
+// TestReplaceTmlGuids only replaces whole values of guid fields.
+func TestReplaceTmlGuids(t *testing.T) {
+	guids := map[string]string{
+		"lb-original":    "lb-computed",
+		"viz-1-original": "viz-1-computed",
+		"viz-2":          "viz-2-prefix",
+	}
+
+	got, err := replaceTmlGuids(testOriginalLiveboardTml, guids, "YAML")
+	if err != nil {
+		t.Fatalf("replacing guids: %s", err)
+	}
+
+	values, err := tmlGuidPaths(got)
+	if err != nil {
+		t.Fatalf("reading guid paths: %s", err)
+	}
+
+	want := []tmlGuidValue{
+		{Path: "guid", Value: "lb-computed"},
+		{Path: "liveboard.visualizations[Viz_1].viz_guid", Value: "viz-1-computed"},
+		{Path: "liveboard.visualizations[Viz_2].viz_guid", Value: "viz-2-original"},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got %+v, want %+v", values, want)
+	}
+
+	// The description mentions a guid, but isn't a guid field
+	if !strings.Contains(got, "description: Copied from lb-original") {
+		t.Errorf("description was changed:\n%s", got)
+	}
+
+	// Nothing to replace keeps the TML as written
+	if got, err := replaceTmlGuids(testOriginalLiveboardTml, map[string]string{"other": "guid"}, "YAML"); err != nil || got != testOriginalLiveboardTml {
+		t.Errorf("got %q, %v without matching guids", got, err)
+	}
+}
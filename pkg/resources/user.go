@@ -0,0 +1,477 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &UserResource{}
+	_ resource.ResourceWithConfigure   = &UserResource{}
+	_ resource.ResourceWithImportState = &UserResource{}
+)
+
+func NewUserResource() resource.Resource {
+	return &UserResource{}
+}
+
+type UserResource struct {
+	client *thoughtspot.Client
+}
+
+type UserResourceModel struct {
+	ID                       types.String `tfsdk:"id"`
+	Name                     types.String `tfsdk:"name"`
+	DisplayName              types.String `tfsdk:"display_name"`
+	Password                 types.String `tfsdk:"password"`
+	PasswordVersion          types.Int64  `tfsdk:"password_version"`
+	Email                    types.String `tfsdk:"email"`
+	AccountType              types.String `tfsdk:"account_type"`
+	AccountStatus            types.String `tfsdk:"account_status"`
+	Visibility               types.String `tfsdk:"visibility"`
+	Orgs                     types.Set    `tfsdk:"orgs"`
+	Groups                   types.Set    `tfsdk:"groups"`
+	PreferredLocale          types.String `tfsdk:"preferred_locale"`
+	NotifyOnShare            types.Bool   `tfsdk:"notify_on_share"`
+	ShowOnboardingExperience types.Bool   `tfsdk:"show_onboarding_experience"`
+	OnDestroy                types.String `tfsdk:"on_destroy"`
+	TransferOwnershipTo      types.String `tfsdk:"transfer_ownership_to"`
+}
+
+var (
+	userAccountTypes    = []string{"LOCAL_USER", "LDAP_USER", "SAML_USER", "OIDC_USER", "REMOTE_USER"}
+	userAccountStatuses = []string{"ACTIVE", "INACTIVE", "EXPIRED", "LOCKED", "PENDING"}
+	userVisibilities    = []string{"SHARABLE", "NON_SHARABLE"}
+)
+
+// Metadata returns the resource type name.
+func (r *UserResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+// Schema defines the schema for the resource.
+func (r *UserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the user, used to log in.",
+			},
+			"display_name": schema.StringAttribute{
+				Required:    true,
+				Description: "Display name of the user.",
+			},
+			"password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Description: "Password of the user. It is write-only, so it is never stored in state and requires Terraform 1.11 or later. The password is set when the user is created, change `password_version` to reset it.",
+			},
+			"password_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Version of `password`. Changing it resets the password of the user to `password`.",
+				Validators: []validator.Int64{
+					int64validator.AlsoRequires(path.MatchRoot("password")),
+				},
+			},
+			"email": schema.StringAttribute{
+				Optional:    true,
+				Description: "Email address of the user.",
+			},
+			"account_type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("LOCAL_USER"),
+				Description: "Type of the account. Accepts `LOCAL_USER`, `LDAP_USER`, `SAML_USER`, `OIDC_USER`, `REMOTE_USER`. Defaults to `LOCAL_USER`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(userAccountTypes...),
+				},
+			},
+			"account_status": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("ACTIVE"),
+				Description: "Status of the account. Accepts `ACTIVE`, `INACTIVE`, `EXPIRED`, `LOCKED`, `PENDING`. Defaults to `ACTIVE`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(userAccountStatuses...),
+				},
+			},
+			"visibility": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("SHARABLE"),
+				Description: "Whether other users can share objects with the user. Accepts `SHARABLE`, `NON_SHARABLE`. Defaults to `SHARABLE`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(userVisibilities...),
+				},
+			},
+			"orgs": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Names of the orgs the user belongs to, if not defined Terraform will not manage the org memberships of the user",
+			},
+			"groups": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Names of the groups the user belongs to, if not defined Terraform will not manage the group memberships of the user",
+			},
+			"preferred_locale": schema.StringAttribute{
+				Optional:    true,
+				Description: "Locale of the user, such as `en-US`. Uses the locale of the browser if not set.",
+			},
+			"notify_on_share": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the user is notified by email when objects are shared with them. Defaults to `true`.",
+			},
+			"show_onboarding_experience": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the onboarding experience is shown to the user. Defaults to `true`.",
+			},
+			"on_destroy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("delete"),
+				Description: "What to do with the user on destroy. `deactivate` sets the account status to `INACTIVE` and keeps the user, `delete` deletes it. Defaults to `delete`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("deactivate", "delete"),
+				},
+			},
+			"transfer_ownership_to": schema.StringAttribute{
+				Optional:    true,
+				Description: "Name or GUID of the user, such as a service account, to transfer the objects owned by the user to on destroy, so they are not orphaned.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *UserResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+// userMemberships returns the orgs and groups of the plan. Null sets return
+// nil, so the memberships of the user are left as they are.
+func userMemberships(ctx context.Context, plan UserResourceModel) ([]string, []string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var orgs []string
+	if !plan.Orgs.IsNull() {
+		orgs = make([]string, 0, len(plan.Orgs.Elements()))
+		diags.Append(plan.Orgs.ElementsAs(ctx, &orgs, false)...)
+	}
+
+	var groups []string
+	if !plan.Groups.IsNull() {
+		groups = make([]string, 0, len(plan.Groups.Elements()))
+		diags.Append(plan.Groups.ElementsAs(ctx, &groups, false)...)
+	}
+
+	return orgs, groups, diags
+}
+
+// Create a new resource.
+func (r *UserResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan UserResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The password is write-only, so it is only in the config
+	var password types.String
+	diags = req.Config.GetAttribute(ctx, path.Root("password"), &password)
+	resp.Diagnostics.Append(diags...)
+
+	orgs, groups, diags := userMemberships(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cr := models.CreateUserRequest{
+		Name:                     plan.Name.ValueString(),
+		DisplayName:              plan.DisplayName.ValueString(),
+		Password:                 password.ValueString(),
+		Email:                    plan.Email.ValueString(),
+		AccountType:              plan.AccountType.ValueString(),
+		AccountStatus:            plan.AccountStatus.ValueString(),
+		Visibility:               plan.Visibility.ValueString(),
+		OrgIdentifiers:           orgs,
+		GroupIdentifiers:         groups,
+		PreferredLocale:          plan.PreferredLocale.ValueString(),
+		NotifyOnShare:            plan.NotifyOnShare.ValueBool(),
+		ShowOnboardingExperience: plan.ShowOnboardingExperience.ValueBool(),
+	}
+
+	c, err := r.client.CreateUser(cr)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error creating User",
+			"Could not create user, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// Map response body to schema and populate Computed attribute values
+	plan.ID = types.StringValue(c.Id)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *UserResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state UserResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	c, err := r.client.SearchUsers(models.SearchUsersRequest{
+		UserIdentifier: state.ID.ValueString(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading User",
+			"Could not read User ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	if len(c) == 0 {
+		resp.State.RemoveResource(ctx)
+
+		return
+	}
+
+	m := c[0]
+
+	state.ID = types.StringValue(m.Id)
+	state.Name = types.StringValue(m.Name)
+	state.DisplayName = types.StringValue(m.DisplayName)
+	state.AccountType = types.StringValue(m.AccountType)
+	state.AccountStatus = types.StringValue(m.AccountStatus)
+	state.Visibility = types.StringValue(m.Visibility)
+	state.NotifyOnShare = types.BoolValue(m.NotifyOnShare)
+	state.ShowOnboardingExperience = types.BoolValue(m.ShowOnboardingExperience)
+
+	if m.Email != "" || !state.Email.IsNull() {
+		state.Email = types.StringValue(m.Email)
+	}
+	if m.PreferredLocale != "" || !state.PreferredLocale.IsNull() {
+		state.PreferredLocale = types.StringValue(m.PreferredLocale)
+	}
+
+	if !state.Orgs.IsNull() {
+		orgs := make([]string, len(m.Orgs))
+		for i := range m.Orgs {
+			orgs[i] = m.Orgs[i].Name
+		}
+		state.Orgs, diags = types.SetValueFrom(ctx, types.StringType, orgs)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	if !state.Groups.IsNull() {
+		groups := make([]string, len(m.UserGroups))
+		for i := range m.UserGroups {
+			groups[i] = m.UserGroups[i].Name
+		}
+		state.Groups, diags = types.SetValueFrom(ctx, types.StringType, groups)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	// Imported users don't have on_destroy yet
+	if state.OnDestroy.IsNull() {
+		state.OnDestroy = types.StringValue("delete")
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the user and resets its password when password_version
+// changes.
+func (r *UserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan, state UserResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orgs, groups, diags := userMemberships(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	cr := models.UpdateUserRequest{
+		Name:                     plan.Name.ValueString(),
+		DisplayName:              plan.DisplayName.ValueString(),
+		Email:                    plan.Email.ValueString(),
+		AccountType:              plan.AccountType.ValueString(),
+		AccountStatus:            plan.AccountStatus.ValueString(),
+		Visibility:               plan.Visibility.ValueString(),
+		OrgIdentifiers:           orgs,
+		GroupIdentifiers:         groups,
+		PreferredLocale:          plan.PreferredLocale.ValueString(),
+		NotifyOnShare:            plan.NotifyOnShare.ValueBool(),
+		ShowOnboardingExperience: plan.ShowOnboardingExperience.ValueBool(),
+	}
+
+	err := r.client.UpdateUser(plan.ID.ValueString(), cr)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error updating User",
+			"Could not update User, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	// The password is write-only, so only a new password_version resets it
+	var password types.String
+	diags = req.Config.GetAttribute(ctx, path.Root("password"), &password)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !password.IsNull() && !plan.PasswordVersion.Equal(state.PasswordVersion) {
+		err := r.client.ResetUserPassword(models.ResetUserPasswordRequest{
+			UserIdentifier: plan.ID.ValueString(),
+			NewPassword:    password.ValueString(),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error updating User",
+				"Could not reset the password of User, unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *UserResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state UserResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Hand the objects of the user over first, so they are not orphaned
+	if !state.TransferOwnershipTo.IsNull() {
+		err := r.client.TransferUserOwnership(models.TransferUserOwnershipRequest{
+			CurrentOwnerIdentifier: state.ID.ValueString(),
+			NewOwnerIdentifier:     state.TransferOwnershipTo.ValueString(),
+		})
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("transfer_ownership_to"),
+				"Error deleting User",
+				"Could not transfer the objects of User to '"+state.TransferOwnershipTo.ValueString()+"', unexpected error: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if state.OnDestroy.ValueString() == "deactivate" {
+		err := r.client.UpdateUser(state.ID.ValueString(), models.UpdateUserRequest{
+			Name:                     state.Name.ValueString(),
+			DisplayName:              state.DisplayName.ValueString(),
+			Email:                    state.Email.ValueString(),
+			AccountType:              state.AccountType.ValueString(),
+			AccountStatus:            "INACTIVE",
+			Visibility:               state.Visibility.ValueString(),
+			PreferredLocale:          state.PreferredLocale.ValueString(),
+			NotifyOnShare:            state.NotifyOnShare.ValueBool(),
+			ShowOnboardingExperience: state.ShowOnboardingExperience.ValueBool(),
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting User",
+				"Could not deactivate User, unexpected error: "+err.Error(),
+			)
+		}
+		return
+	}
+
+	err := r.client.DeleteUser(state.ID.ValueString())
+
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error deleting User",
+			"Could not delete User, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *UserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Retrieve import ID and save to id attribute
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
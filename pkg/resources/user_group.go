@@ -11,17 +11,18 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/setplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &UserGroupResource{}
-	_ resource.ResourceWithConfigure = &UserGroupResource{}
+	_ resource.Resource                 = &UserGroupResource{}
+	_ resource.ResourceWithConfigure    = &UserGroupResource{}
+	_ resource.ResourceWithUpgradeState = &UserGroupResource{}
 	// _ resource.ResourceWithImportState = &spaceResource{}
 )
 
@@ -37,14 +38,14 @@ type UserGroupResourceModel struct {
 	ID                types.String `tfsdk:"id"`
 	Name              types.String `tfsdk:"name"`
 	DisplayName       types.String `tfsdk:"display_name"`
-	DefaultLiveboards types.List   `tfsdk:"default_liveboards"`
+	DefaultLiveboards types.Set    `tfsdk:"default_liveboards"`
 	Description       types.String `tfsdk:"description"`
-	Privileges        types.List   `tfsdk:"privileges"`
-	SubGroups         types.List   `tfsdk:"sub_groups"`
+	Privileges        types.Set    `tfsdk:"privileges"`
+	SubGroups         types.Set    `tfsdk:"sub_groups"`
 	Type              types.String `tfsdk:"type"`
-	Users             types.List   `tfsdk:"users"`
+	Users             types.Set    `tfsdk:"users"`
 	Visibility        types.String `tfsdk:"visibility"`
-	Roles             types.List   `tfsdk:"roles"`
+	Roles             types.Set    `tfsdk:"roles"`
 	RbacEnabled       types.Bool   `tfsdk:"rbac_enabled"`
 }
 
@@ -56,6 +57,7 @@ func (r *UserGroupResource) Metadata(_ context.Context, req resource.MetadataReq
 // Schema defines the schema for the resource.
 func (r *UserGroupResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		Version: 1,
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -69,11 +71,11 @@ func (r *UserGroupResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			"display_name": schema.StringAttribute{
 				Required: true,
 			},
-			"default_liveboards": schema.ListAttribute{
+			"default_liveboards": schema.SetAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
 				Computed:    true,
-				Default: listdefault.StaticValue(types.ListValueMust(
+				Default: setdefault.StaticValue(types.SetValueMust(
 					types.StringType,
 					[]attr.Value{},
 				)),
@@ -81,46 +83,46 @@ func (r *UserGroupResource) Schema(_ context.Context, _ resource.SchemaRequest,
 			"description": schema.StringAttribute{
 				Optional: true,
 			},
-			"privileges": schema.ListAttribute{
+			"privileges": schema.SetAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
 				Computed:    true,
-				Default: listdefault.StaticValue(types.ListValueMust(
+				Default: setdefault.StaticValue(types.SetValueMust(
 					types.StringType,
 					[]attr.Value{},
 				)),
-				PlanModifiers: []planmodifier.List{
-					listplanmodifier.UseStateForUnknown(),
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
 				},
 			},
-			"sub_groups": schema.ListAttribute{
+			"sub_groups": schema.SetAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
 				Computed:    true,
-				Default: listdefault.StaticValue(types.ListValueMust(
+				Default: setdefault.StaticValue(types.SetValueMust(
 					types.StringType,
 					[]attr.Value{},
 				)),
-				PlanModifiers: []planmodifier.List{
-					listplanmodifier.UseStateForUnknown(),
+				PlanModifiers: []planmodifier.Set{
+					setplanmodifier.UseStateForUnknown(),
 				},
 			},
 			"type": schema.StringAttribute{
 				Optional: true,
 			},
-			"users": schema.ListAttribute{
+			"users": schema.SetAttribute{
 				ElementType: types.StringType,
-				Description: "List of user names to add to the user group, if not defined Terraform will not manage user assignment to the group",
+				Description: "Names of the users to add to the user group, if not defined Terraform will not manage user assignment to the group",
 				Optional:    true,
 			},
 			"visibility": schema.StringAttribute{
 				Optional: true,
 			},
-			"roles": schema.ListAttribute{
+			"roles": schema.SetAttribute{
 				ElementType: types.StringType,
 				Optional:    true,
 				Computed:    true,
-				Default: listdefault.StaticValue(types.ListValueMust(
+				Default: setdefault.StaticValue(types.SetValueMust(
 					types.StringType,
 					[]attr.Value{},
 				)),
@@ -137,24 +139,38 @@ func (r *UserGroupResource) Schema(_ context.Context, _ resource.SchemaRequest,
 	}
 }
 
+// UpgradeState upgrades state from prior schema versions.
+func (r *UserGroupResource) UpgradeState(ctx context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 stored these attributes as lists, so reordering them
+		// showed as a diff
+		0: rawStateUpgrader(func(state map[string]any) error {
+			for _, key := range []string{"default_liveboards", "privileges", "sub_groups", "users", "roles"} {
+				uniqueStateList(state, key)
+			}
+			return nil
+		}),
+	}
+}
+
 // Configure adds the provider configured client to the resource.
 func (r *UserGroupResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
 	if req.ProviderData == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*thoughtspot.Client)
+	data, ok := req.ProviderData.(*ProviderData)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *qlikcloud.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 
 		return
 	}
 
-	r.client = client
+	r.client = data.Client
 }
 
 // Create a new resource.
@@ -287,9 +303,9 @@ func (r *UserGroupResource) Read(ctx context.Context, req resource.ReadRequest,
 	state.DisplayName = types.StringValue(m.DisplayName)
 	state.Description = types.StringValue(m.Description)
 	state.Type = types.StringValue(m.Type)
-	state.Users, _ = types.ListValueFrom(ctx, types.StringType, users)
-	state.SubGroups, _ = types.ListValueFrom(ctx, types.StringType, sg)
-	state.DefaultLiveboards, _ = types.ListValueFrom(ctx, types.StringType, dl)
+	state.Users, _ = types.SetValueFrom(ctx, types.StringType, users)
+	state.SubGroups, _ = types.SetValueFrom(ctx, types.StringType, sg)
+	state.DefaultLiveboards, _ = types.SetValueFrom(ctx, types.StringType, dl)
 	state.Visibility = types.StringValue(m.Visibility)
 
 	if state.RbacEnabled.ValueBool() {
@@ -297,18 +313,18 @@ func (r *UserGroupResource) Read(ctx context.Context, req resource.ReadRequest,
 		for i := range m.Roles {
 			roles[i] = m.Roles[i].Id
 		}
-		state.Roles, diags = types.ListValueFrom(ctx, types.StringType, roles)
+		state.Roles, diags = types.SetValueFrom(ctx, types.StringType, roles)
 		resp.Diagnostics.Append(diags...)
-		state.Privileges = types.ListValueMust(
+		state.Privileges = types.SetValueMust(
 			types.StringType,
 			[]attr.Value{},
 		)
 	} else {
-		state.Roles = types.ListValueMust(
+		state.Roles = types.SetValueMust(
 			types.StringType,
 			[]attr.Value{},
 		)
-		state.Privileges, diags = types.ListValueFrom(ctx, types.StringType, m.Privileges)
+		state.Privileges, diags = types.SetValueFrom(ctx, types.StringType, m.Privileges)
 		resp.Diagnostics.Append(diags...)
 	}
 
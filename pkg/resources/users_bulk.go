@@ -0,0 +1,728 @@
+package resources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	thoughtspot "github.com/daniepett/thoughtspot-sdk-go"
+	"github.com/daniepett/thoughtspot-sdk-go/models"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &UsersBulkResource{}
+	_ resource.ResourceWithConfigure  = &UsersBulkResource{}
+	_ resource.ResourceWithModifyPlan = &UsersBulkResource{}
+	// _ resource.ResourceWithImportState = &UsersBulkResource{}
+)
+
+func NewUsersBulkResource() resource.Resource {
+	return &UsersBulkResource{}
+}
+
+type UsersBulkResource struct {
+	client *thoughtspot.Client
+}
+
+type UsersBulkResourceModel struct {
+	ID                     types.String `tfsdk:"id"`
+	Users                  types.List   `tfsdk:"users"`
+	CsvFile                types.String `tfsdk:"csv_file"`
+	CsvHash                types.String `tfsdk:"csv_hash"`
+	DefaultPassword        types.String `tfsdk:"default_password"`
+	PasswordVersion        types.Int64  `tfsdk:"password_version"`
+	DeleteUnspecifiedUsers types.Bool   `tfsdk:"delete_unspecified_users"`
+	UserIds                types.Map    `tfsdk:"user_ids"`
+}
+
+type UsersBulkUserModel struct {
+	Name          types.String `tfsdk:"name"`
+	DisplayName   types.String `tfsdk:"display_name"`
+	Email         types.String `tfsdk:"email"`
+	Password      types.String `tfsdk:"password"`
+	AccountType   types.String `tfsdk:"account_type"`
+	AccountStatus types.String `tfsdk:"account_status"`
+	Visibility    types.String `tfsdk:"visibility"`
+	Orgs          types.Set    `tfsdk:"orgs"`
+	Groups        types.Set    `tfsdk:"groups"`
+}
+
+// usersCsvColumns are the columns accepted in csv_file, matching the
+// attributes of users. orgs and groups are separated by |.
+var usersCsvColumns = []string{"name", "display_name", "email", "password", "account_type", "account_status", "visibility", "orgs", "groups"}
+
+// Metadata returns the resource type name.
+func (r *UsersBulkResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_users_bulk"
+}
+
+// Schema defines the schema for the resource.
+func (r *UsersBulkResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Version: 0,
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"users": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Users to import.",
+				Validators: []validator.List{
+					listvalidator.ExactlyOneOf(path.MatchRoot("csv_file")),
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the user, used to log in.",
+						},
+						"display_name": schema.StringAttribute{
+							Required:    true,
+							Description: "Display name of the user.",
+						},
+						"email": schema.StringAttribute{
+							Optional:    true,
+							Description: "Email address of the user.",
+						},
+						"password": schema.StringAttribute{
+							Optional:    true,
+							Sensitive:   true,
+							WriteOnly:   true,
+							Description: "Password of the user. Uses `default_password` if not set. It is write-only, so it is never stored in state and requires Terraform 1.11 or later.",
+						},
+						"account_type": schema.StringAttribute{
+							Optional:    true,
+							Description: "Type of the account. Accepts `LOCAL_USER`, `LDAP_USER`, `SAML_USER`, `OIDC_USER`, `REMOTE_USER`",
+							Validators: []validator.String{
+								stringvalidator.OneOf(userAccountTypes...),
+							},
+						},
+						"account_status": schema.StringAttribute{
+							Optional:    true,
+							Description: "Status of the account. Accepts `ACTIVE`, `INACTIVE`, `EXPIRED`, `LOCKED`, `PENDING`",
+							Validators: []validator.String{
+								stringvalidator.OneOf(userAccountStatuses...),
+							},
+						},
+						"visibility": schema.StringAttribute{
+							Optional:    true,
+							Description: "Whether other users can share objects with the user. Accepts `SHARABLE`, `NON_SHARABLE`",
+							Validators: []validator.String{
+								stringvalidator.OneOf(userVisibilities...),
+							},
+						},
+						"orgs": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "Names of the orgs the user belongs to.",
+						},
+						"groups": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+							Description: "Names of the groups the user belongs to.",
+						},
+					},
+				},
+			},
+			"csv_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a CSV file of users to import. The header row names the columns, out of `" + strings.Join(usersCsvColumns, "`, `") + "`. Orgs and groups are separated by `|`. Only a hash of the content is stored in state.",
+			},
+			"csv_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA-256 hash of the content of `csv_file`.",
+			},
+			"default_password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				WriteOnly:   true,
+				Description: "Password of the imported users which don't have a password. It is write-only, so it is never stored in state and requires Terraform 1.11 or later.",
+			},
+			"password_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Version of the passwords. Passwords are only sent when the users are imported, change it to import the users again with the configured passwords.",
+			},
+			"delete_unspecified_users": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Delete all users of the cluster which are not imported by this resource, except the administrators and system users. Defaults to `false`.",
+			},
+			"user_ids": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Map of user name to the GUID of the imported user.",
+			},
+		},
+	}
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *UsersBulkResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	data, ok := req.ProviderData.(*ProviderData)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *resources.ProviderData, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = data.Client
+}
+
+// readUsersCsv reads the users of a CSV file and returns them with the hash of
+// the file.
+func readUsersCsv(filePath string) ([]models.ImportUserType, string, error) {
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+
+	records, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil {
+		return nil, "", err
+	}
+	if len(records) == 0 {
+		return nil, "", fmt.Errorf("missing header row")
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		column = strings.TrimSpace(column)
+		known := false
+		for _, c := range usersCsvColumns {
+			known = known || c == column
+		}
+		if !known {
+			return nil, "", fmt.Errorf("unknown column %q, expected one of %s", column, strings.Join(usersCsvColumns, ", "))
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"name", "display_name"} {
+		if _, ok := columns[column]; !ok {
+			return nil, "", fmt.Errorf("missing column %q", column)
+		}
+	}
+
+	var users []models.ImportUserType
+	for line, record := range records[1:] {
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		list := func(column string) []string {
+			var values []string
+			for _, v := range strings.Split(value(column), "|") {
+				if v = strings.TrimSpace(v); v != "" {
+					values = append(values, v)
+				}
+			}
+			return values
+		}
+
+		if value("name") == "" || value("display_name") == "" {
+			return nil, "", fmt.Errorf("line %d: name and display_name are required", line+2)
+		}
+
+		users = append(users, models.ImportUserType{
+			UserIdentifier:   value("name"),
+			DisplayName:      value("display_name"),
+			Email:            value("email"),
+			Password:         value("password"),
+			AccountType:      value("account_type"),
+			AccountStatus:    value("account_status"),
+			Visibility:       value("visibility"),
+			OrgIdentifiers:   list("orgs"),
+			GroupIdentifiers: list("groups"),
+		})
+	}
+
+	return users, hash, nil
+}
+
+// bulkUsers returns the users to import from users or csv_file, and sets
+// csv_hash on the model.
+func bulkUsers(ctx context.Context, m *UsersBulkResourceModel) ([]models.ImportUserType, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var users []models.ImportUserType
+	var p path.Path
+
+	if !m.CsvFile.IsNull() {
+		p = path.Root("csv_file")
+
+		u, hash, err := readUsersCsv(m.CsvFile.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				p,
+				"Error reading CSV file",
+				"Could not read users from "+m.CsvFile.ValueString()+": "+err.Error(),
+			)
+			return nil, diags
+		}
+
+		users = u
+		m.CsvHash = types.StringValue(hash)
+	} else {
+		p = path.Root("users")
+
+		var list []UsersBulkUserModel
+		diags.Append(m.Users.ElementsAs(ctx, &list, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+
+		for _, u := range list {
+			var orgs, groups []string
+			diags.Append(u.Orgs.ElementsAs(ctx, &orgs, false)...)
+			diags.Append(u.Groups.ElementsAs(ctx, &groups, false)...)
+
+			users = append(users, models.ImportUserType{
+				UserIdentifier:   u.Name.ValueString(),
+				DisplayName:      u.DisplayName.ValueString(),
+				Email:            u.Email.ValueString(),
+				Password:         u.Password.ValueString(),
+				AccountType:      u.AccountType.ValueString(),
+				AccountStatus:    u.AccountStatus.ValueString(),
+				Visibility:       u.Visibility.ValueString(),
+				OrgIdentifiers:   orgs,
+				GroupIdentifiers: groups,
+			})
+		}
+
+		m.CsvHash = types.StringNull()
+	}
+
+	seen := map[string]bool{}
+	for _, u := range users {
+		if seen[u.UserIdentifier] {
+			diags.AddAttributeError(
+				p,
+				"Duplicate user",
+				"User '"+u.UserIdentifier+"' is listed more than once.",
+			)
+		}
+		seen[u.UserIdentifier] = true
+	}
+
+	return users, diags
+}
+
+// ModifyPlan imports the users again when csv_file changed, or when imported
+// users were deleted outside of Terraform.
+func (r *UsersBulkResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan UsersBulkResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// The users are read during apply if they aren't known yet
+	if plan.Users.IsUnknown() || plan.CsvFile.IsUnknown() {
+		return
+	}
+
+	users, diags := bulkUsers(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state UsersBulkResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		ids := map[string]string{}
+		if !state.UserIds.IsNull() && !state.UserIds.IsUnknown() {
+			resp.Diagnostics.Append(state.UserIds.ElementsAs(ctx, &ids, false)...)
+		}
+
+		changed := !plan.CsvHash.Equal(state.CsvHash)
+		for _, u := range users {
+			if ids[u.UserIdentifier] == "" {
+				changed = true
+			}
+		}
+
+		if changed {
+			plan.UserIds = types.MapUnknown(types.StringType)
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// bulkUserPasswords sets the passwords of the users from the config, as
+// write-only passwords are null in the plan, and returns default_password.
+// Users read from csv_file keep the password of the file.
+func bulkUserPasswords(ctx context.Context, config tfsdk.Config, users []models.ImportUserType) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var defaultPassword types.String
+	diags.Append(config.GetAttribute(ctx, path.Root("default_password"), &defaultPassword)...)
+
+	var list []UsersBulkUserModel
+	diags.Append(config.GetAttribute(ctx, path.Root("users"), &list)...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	// The list of the config is the list of the plan
+	if len(list) == len(users) {
+		for i, u := range list {
+			users[i].Password = u.Password.ValueString()
+		}
+	}
+
+	return defaultPassword.ValueString(), diags
+}
+
+// importUsers imports the users of the plan and returns the GUIDs of the
+// imported users by name. Added and updated users are logged, deleted users
+// are reported as warnings and users missing from the response as errors
+// against the attribute they come from.
+func (r *UsersBulkResource) importUsers(ctx context.Context, plan *UsersBulkResourceModel, config tfsdk.Config) (map[string]string, diag.Diagnostics) {
+	plannedHash := plan.CsvHash
+
+	users, diags := bulkUsers(ctx, plan)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	defaultPassword, d := bulkUserPasswords(ctx, config, users)
+	diags.Append(d...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	if !plan.CsvFile.IsNull() && !plannedHash.IsUnknown() && !plannedHash.Equal(plan.CsvHash) {
+		diags.AddAttributeError(
+			path.Root("csv_file"),
+			"CSV file changed after plan",
+			"The content of "+plan.CsvFile.ValueString()+" changed after the plan was created. Run the plan again.",
+		)
+		return nil, diags
+	}
+
+	c, err := r.client.ImportUsers(models.ImportUsersRequest{
+		Users:                  users,
+		DefaultPassword:        defaultPassword,
+		DeleteUnspecifiedUsers: plan.DeleteUnspecifiedUsers.ValueBool(),
+	})
+	if err != nil {
+		diags.AddError(
+			"Error importing Users",
+			"Could not import users, unexpected error: "+err.Error(),
+		)
+		return nil, diags
+	}
+
+	ids := map[string]string{}
+	for _, u := range c.UsersAdded {
+		ids[u.Name] = u.Id
+		tflog.Info(ctx, "Added user", map[string]interface{}{"name": u.Name, "id": u.Id})
+	}
+	for _, u := range c.UsersUpdated {
+		ids[u.Name] = u.Id
+		tflog.Info(ctx, "Updated user", map[string]interface{}{"name": u.Name, "id": u.Id})
+	}
+
+	for _, u := range c.UsersDeleted {
+		diags.AddAttributeWarning(
+			path.Root("delete_unspecified_users"),
+			"Deleted User",
+			"User '"+u.Name+"' was not imported and has been deleted.",
+		)
+	}
+
+	for i, u := range users {
+		if _, ok := ids[u.UserIdentifier]; ok {
+			continue
+		}
+
+		p := path.Root("csv_file")
+		if plan.CsvFile.IsNull() {
+			p = path.Root("users").AtListIndex(i)
+		}
+
+		diags.AddAttributeError(
+			p,
+			"Error importing Users",
+			"User '"+u.UserIdentifier+"' was neither added nor updated, it is retried on the next apply.",
+		)
+	}
+
+	return ids, diags
+}
+
+// userSearchPageSize is the number of users or groups requested per page of
+// a search.
+const userSearchPageSize = 100
+
+// searchAllUsers returns the users matching the request, paging through the
+// search until a page isn't full.
+func searchAllUsers(client *thoughtspot.Client, req models.SearchUsersRequest) ([]models.User, error) {
+	var users []models.User
+	for offset := 0; ; offset += userSearchPageSize {
+		req.RecordOffset = strconv.Itoa(offset)
+		req.RecordSize = strconv.Itoa(userSearchPageSize)
+
+		c, err := client.SearchUsers(req)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, c...)
+
+		if len(c) < userSearchPageSize {
+			return users, nil
+		}
+	}
+}
+
+// userSearchBatchSize is the number of users searched at once by findUsers.
+const userSearchBatchSize = 10
+
+// findUsers reports which of the users still exist. Only the given users are
+// searched, in concurrent batches, rather than every user of the cluster.
+func findUsers(client *thoughtspot.Client, ids []string) (map[string]bool, error) {
+	found := map[string]bool{}
+
+	for start := 0; start < len(ids); start += userSearchBatchSize {
+		batch := ids[start:min(start+userSearchBatchSize, len(ids))]
+
+		exists := make([]bool, len(batch))
+		errs := make([]error, len(batch))
+
+		var wg sync.WaitGroup
+		for i, id := range batch {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				c, err := client.SearchUsers(models.SearchUsersRequest{
+					UserIdentifier: id,
+				})
+				exists[i], errs[i] = len(c) > 0, err
+			}()
+		}
+		wg.Wait()
+
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		for i, id := range batch {
+			found[id] = exists[i]
+		}
+	}
+
+	return found, nil
+}
+
+// Create a new resource.
+func (r *UsersBulkResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan UsersBulkResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, diags := r.importUsers(ctx, &plan, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if ids == nil {
+		return
+	}
+
+	// Users imported before an error are kept in state, so they are
+	// deleted when the tainted resource is replaced
+	names := make([]string, 0, len(ids))
+	for name := range ids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sum := sha256.Sum256([]byte(strings.Join(names, "\n")))
+	plan.ID = types.StringValue(hex.EncodeToString(sum[:8]))
+	plan.UserIds, diags = types.MapValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	// Set state to fully populated data
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read resource information.
+func (r *UsersBulkResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state UsersBulkResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := map[string]string{}
+	resp.Diagnostics.Append(state.UserIds.ElementsAs(ctx, &ids, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var userIds []string
+	for _, id := range ids {
+		userIds = append(userIds, id)
+	}
+
+	found, err := findUsers(r.client, userIds)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Users",
+			"Could not read users: "+err.Error(),
+		)
+		return
+	}
+
+	// Users deleted outside of Terraform are dropped, so they are imported
+	// again on the next apply
+	for name, id := range ids {
+		if !found[id] {
+			delete(ids, name)
+		}
+	}
+
+	state.UserIds, diags = types.MapValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *UsersBulkResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// Retrieve values from plan
+	var plan, state UsersBulkResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids, diags := r.importUsers(ctx, &plan, req.Config)
+	resp.Diagnostics.Append(diags...)
+	if ids == nil {
+		return
+	}
+
+	// Delete users which are no longer imported
+	stateIds := map[string]string{}
+	resp.Diagnostics.Append(state.UserIds.ElementsAs(ctx, &stateIds, false)...)
+	for name, id := range stateIds {
+		if _, ok := ids[name]; ok || id == "" {
+			continue
+		}
+
+		err := r.client.DeleteUser(id)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting User",
+				"Could not delete User '"+name+"', unexpected error: "+err.Error(),
+			)
+			ids[name] = id
+		}
+	}
+
+	plan.UserIds, diags = types.MapValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+func (r *UsersBulkResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Retrieve values from state
+	var state UsersBulkResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ids := map[string]string{}
+	resp.Diagnostics.Append(state.UserIds.ElementsAs(ctx, &ids, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	names := make([]string, 0, len(ids))
+	for name := range ids {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err := r.client.DeleteUser(ids[name])
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error deleting User",
+				"Could not delete User '"+name+"', unexpected error: "+err.Error(),
+			)
+		}
+	}
+}